@@ -0,0 +1,126 @@
+package apply
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/input"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/state"
+)
+
+func NewCommand() *cobra.Command {
+	var file string
+	var prune, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "apply -f portmap.yaml",
+		Short: "Create, update and delete configs and mappings to match a file",
+		Long: "Compare the configs and mappings described in a YAML or JSON file with the account,\n" +
+			"print the plan and carry it out. Configs are matched by name, mappings by protocol,\n" +
+			"hostname and port. Without --prune, configs and mappings missing from the file are kept;\n" +
+			"with it, what would be deleted is listed and confirmation is asked for first, unless --yes is given.",
+		Example: "  portmap apply -f portmap.yaml --dry-run\n" +
+			"  portmap apply -f portmap.yaml --prune --yes",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := cmd.Flag("token").Value.String()
+			outputFormat := cmd.Flag("output").Value.String()
+
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			desired, err := state.LoadFile(file)
+			if err != nil {
+				return err
+			}
+			if err := state.Validate(desired); err != nil {
+				return fmt.Errorf("invalid %s: %w", file, err)
+			}
+
+			// The plan is made against the account as it is now
+			api.SetCacheTTL(0)
+			current, err := state.Fetch(api.NewClient(token))
+			if err != nil {
+				return err
+			}
+
+			plan, err := state.Diff(desired, current, prune)
+			if err != nil {
+				return err
+			}
+
+			if format == output.Text {
+				for _, a := range plan.Actions {
+					fmt.Println(a)
+				}
+				fmt.Println(plan.Summary())
+			}
+			dryRun := cmd.Flag("dry-run").Value.String() == "true"
+			if dryRun || len(plan.Actions) == 0 {
+				if format == output.Text {
+					return nil
+				}
+				return output.Print(map[string]interface{}{
+					"status":  "success",
+					"dry_run": dryRun,
+					"actions": plan.Actions,
+				}, output.Options{Format: format, Kind: output.KindPlan})
+			}
+
+			// Deletes can't be undone, so they are confirmed as everywhere else
+			if deletes := plan.Deletes(); len(deletes) > 0 && !yes {
+				fmt.Fprintln(os.Stderr, "These will be deleted:")
+				for _, a := range deletes {
+					fmt.Fprintf(os.Stderr, "  %s\n", a)
+				}
+				confirmed, err := input.Confirm("Delete?")
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			clientFor := func(region string) api.Client {
+				if region == "" || region == "default" {
+					return api.NewClient(token)
+				}
+				return api.NewClientWithBaseURL(token, regions.APIURL(region))
+			}
+			err = plan.Apply(clientFor, func(a state.Action) {
+				if format == output.Text {
+					fmt.Printf("✓ %s\n", a)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			if format == output.Text {
+				fmt.Println("Apply complete")
+				return nil
+			}
+			return output.Print(map[string]interface{}{
+				"status":  "success",
+				"message": "Apply complete",
+				"actions": plan.Actions,
+			}, output.Options{Format: format, Kind: output.KindPlan})
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "filename", "f", "", "File describing the desired configs and mappings, or - for stdin")
+	cmd.Flags().BoolVar(&prune, "prune", false, "Delete configs and mappings that are not in the file")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.MarkFlagRequired("filename")
+	cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
+
+	return cmd
+}
@@ -1,17 +1,20 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 	"portmap.io/client/internal/api"
-	"portmap.io/client/internal/common"
+	"portmap.io/client/internal/completion"
 	"portmap.io/client/internal/input"
 	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/secret"
 	"portmap.io/client/internal/validation"
+	"portmap.io/client/internal/wireguard"
 	"portmap.io/client/pkg/config"
 )
 
@@ -26,6 +29,7 @@ func NewCommand() *cobra.Command {
 		newCreateCommand(),
 		newShowCommand(),
 		newDeleteCommand(),
+		newRotateKeyCommand(),
 	)
 
 	return cmd
@@ -34,6 +38,9 @@ func NewCommand() *cobra.Command {
 func newListCommand() *cobra.Command {
 	var region, configType string
 	var columns []string
+	var sortBy, filter string
+	var noHeaders, watch bool
+	var interval time.Duration
 
 	cmd := &cobra.Command{
 		Use:          "list",
@@ -43,10 +50,20 @@ func newListCommand() *cobra.Command {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
 
+			if len(columns) == 1 && columns[0] == "help" {
+				output.PrintColumns(os.Stdout, output.ConfigColumns)
+				return nil
+			}
+
 			format, err := output.ParseFormat(outputFormat)
 			if err != nil {
 				return err
 			}
+			opts, err := output.ListOptions(format, output.ConfigColumns, columns, sortBy, filter, noHeaders)
+			if err != nil {
+				return err
+			}
+			opts.Kind = output.KindConfigList
 
 			// Load config to get default region
 			cfg, err := config.LoadConfig(cmd.Flag("env-file").Value.String())
@@ -68,12 +85,24 @@ func newListCommand() *cobra.Command {
 				params["type"] = configType
 			}
 
-			opts := output.Options{
-				Format:  format,
-				Columns: columns,
+			client := api.NewClient(token)
+			if watch {
+				api.SetCacheTTL(0)
+				return output.Watch(cmd.CommandPath(), interval, func() (interface{}, error) {
+					return client.ListConfigs(params)
+				}, opts)
+			}
+
+			// Rows are written as the list arrives unless filtering or sorting needs all of it
+			if streamer, ok := client.(api.ListStreamer); ok && output.Streams(opts) {
+				body, err := streamer.StreamConfigs(params)
+				if err != nil {
+					return err
+				}
+				defer body.Close()
+				return output.PrintStream(body, opts)
 			}
 
-			client := api.NewClient(token)
 			configs, err := client.ListConfigs(params)
 			if err != nil {
 				return err
@@ -83,15 +112,26 @@ func newListCommand() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&region, "region", "", "Filter by region (default, nyc1, fra1, blr1, sin1)")
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Filter by region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
 	cmd.Flags().StringVar(&configType, "type", "", "Filter by type (OpenVPN, SSH, WireGuard)")
-	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to display (comma-separated)")
+	cmd.RegisterFlagCompletionFunc("type", completion.ConfigTypes)
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to display (comma-separated), or help to list them")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "Column to sort by, prefixed with - for descending order")
+	cmd.Flags().StringVar(&filter, "filter", "", "Only show rows matching all conditions, e.g. 'port_from>=8000,hostname=*.dev.portmap.io'")
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "Don't print the header line of text, CSV and TSV output")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Poll and redraw the list, highlighting changes (JSON and NDJSON print change events)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls with --watch")
+	cmd.RegisterFlagCompletionFunc("columns", completion.Columns(output.ConfigColumns))
+	cmd.RegisterFlagCompletionFunc("sort-by", completion.Columns(output.ConfigColumns))
 
 	return cmd
 }
 
 // Update the fetchConfigWithRetry function to properly check for config_file
 func fetchConfigWithRetry(client api.Client, configID string, retries int, delay time.Duration) (map[string]interface{}, error) {
+	// Each poll has to reach the API, a cached "not ready" would be returned again
+	api.SetCacheTTL(0)
 	var lastErr error
 	for i := 0; i <= retries; i++ {
 		if i > 0 {
@@ -118,7 +158,8 @@ func fetchConfigWithRetry(client api.Client, configID string, retries int, delay
 }
 
 // Update the saveConfigFile function signature
-func saveConfigFile(data map[string]interface{}, opts output.Options) error {
+// A non-empty privateKey replaces the one in a WireGuard config_file, for keys generated locally
+func saveConfigFile(data map[string]interface{}, privateKey string, opts output.Options) error {
 	if configFile, exists := data["config_file"]; exists {
 		name, ok := data["name"].(string)
 		if !ok {
@@ -138,9 +179,30 @@ func saveConfigFile(data map[string]interface{}, opts output.Options) error {
 		case "SSH":
 			extension = ".pem"
 		case "WireGuard":
+			// Configs created with --local-key have no private key on the server, so keep the local one
+			if privateKey == "" {
+				if _, serverKey := wireguard.SetPrivateKey(content, ""); serverKey == "" {
+					if existing, err := os.ReadFile(name + extension); err == nil {
+						_, privateKey = wireguard.SetPrivateKey(string(existing), "")
+					}
+				}
+			}
+			if privateKey != "" {
+				content, _ = wireguard.SetPrivateKey(content, privateKey)
+			}
+
 			// Add portmap section with config_id
 			if id, ok := data["id"].(float64); ok {
 				content = fmt.Sprintf("%s\n\n[portmap]\nconfig_id = %.0f\n", content, id)
+
+				// Keep the private key out of the working directory when a secret backend is set up
+				if secret.Configured() {
+					var err error
+					content, err = wireguard.StorePrivateKey(content, fmt.Sprintf("%.0f", id))
+					if err != nil {
+						return fmt.Errorf("failed to store private key: %w", err)
+					}
+				}
 			}
 		}
 
@@ -158,9 +220,19 @@ func saveConfigFile(data map[string]interface{}, opts output.Options) error {
 	return fmt.Errorf("config_file not found in response")
 }
 
+// configFlags are the flags setting the fields of a validation.ConfigSpec
+var configFlags = map[string]string{
+	"name":          "--name",
+	"type":          "--type",
+	"region":        "--region",
+	"openvpn_proto": "--openvpn_proto",
+	"comment":       "--comment",
+}
+
 // Update the create command
 func newCreateCommand() *cobra.Command {
 	var name, configType, openvpnProto, region, comment string
+	var localKey bool
 
 	cmd := &cobra.Command{
 		Use:          "create",
@@ -169,7 +241,6 @@ func newCreateCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
-			reader := bufio.NewReader(os.Stdin)
 
 			// Load config to get default region
 			cfg, err := config.LoadConfig(cmd.Flag("env-file").Value.String())
@@ -177,135 +248,104 @@ func newCreateCommand() *cobra.Command {
 				return err
 			}
 
-			// Interactive mode for required parameters
+			// The configuration as given so far, with its fields named by their flags
+			spec := func() validation.ConfigSpec {
+				return validation.ConfigSpec{
+					Name:         name,
+					Type:         configType,
+					Region:       region,
+					OpenVPNProto: openvpnProto,
+					Comment:      comment,
+				}
+			}
+
+			// Every flag is checked before anything is asked, so all mistakes are
+			// reported at once. Without input, missing flags are mistakes too.
+			prompter := input.NewPrompter()
+			problems := spec().Validate().Rename(configFlags)
+			if prompter.Interactive() {
+				problems = problems.Invalid()
+			}
+			if err := problems.Err(); err != nil {
+				return err
+			}
+
+			// Then ask for what is missing
 			if name == "" {
-				for {
-					name, err = input.PromptForValue(reader, "Configuration name", true)
-					if err != nil {
-						return err
-					}
-					if valid, msg := validation.IsValidName(name); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
+				name, err = prompter.Text("Configuration name", "", validation.IsValidName)
+				if err != nil {
+					return err
 				}
-			} else if valid, msg := validation.IsValidName(name); !valid {
-				return fmt.Errorf("invalid name: %s", msg)
 			}
 
 			if configType == "" {
-				fmt.Println("\nSelect configuration type:")
-				fmt.Println("1. OpenVPN")
-				fmt.Println("2. SSH")
-				fmt.Println("3. WireGuard")
-				for {
-					typeNum, err := input.PromptForValue(reader, "Type number (1-3)", true)
-					if err != nil {
-						return err
-					}
-					typeMap := map[string]string{
-						"1": "OpenVPN",
-						"2": "SSH",
-						"3": "WireGuard",
-					}
-					if t, ok := typeMap[typeNum]; ok {
-						configType = t
-						if valid, msg := validation.IsValidConfigType(configType); !valid {
-							fmt.Printf("Error: %s\n", msg)
-							continue
-						}
-						break
-					}
-					fmt.Println("Error: Invalid type selection")
+				configType, err = prompter.Select("Configuration type", input.Options("OpenVPN", "SSH", "WireGuard"), "")
+				if err != nil {
+					return err
 				}
-			} else if valid, msg := validation.IsValidConfigType(configType); !valid {
-				return fmt.Errorf("invalid type: %s", msg)
 			}
 
 			// Get OpenVPN protocol if needed
 			if configType == "OpenVPN" && openvpnProto == "" {
-				fmt.Println("\nSelect OpenVPN protocol:")
-				fmt.Println("1. tcp")
-				fmt.Println("2. udp")
-				for {
-					protoNum, err := input.PromptForValue(reader, "Protocol number (1-2)", true)
-					if err != nil {
-						return err
-					}
-					protoMap := map[string]string{
-						"1": "tcp",
-						"2": "udp",
-					}
-					if p, ok := protoMap[protoNum]; ok {
-						openvpnProto = p
-						if valid, msg := validation.IsValidOpenVPNProto(openvpnProto); !valid {
-							fmt.Printf("Error: %s\n", msg)
-							continue
-						}
-						break
-					}
-					fmt.Println("Error: Invalid protocol selection")
-				}
-			} else if configType == "OpenVPN" {
-				if valid, msg := validation.IsValidOpenVPNProto(openvpnProto); !valid {
-					return fmt.Errorf("invalid OpenVPN protocol: %s", msg)
+				openvpnProto, err = prompter.Select("OpenVPN protocol", input.Options("tcp", "udp"), "")
+				if err != nil {
+					return err
 				}
 			}
 
 			if region == "" {
-				// Use default region if set
-				if cfg.Region != "" {
+				// Use default region if set, without input the API's default region
+				switch {
+				case cfg.Region != "":
 					region = cfg.Region
-				} else {
-					fmt.Println("\nSelect region:")
-					fmt.Println("1. default")
-					fmt.Println("2. nyc1")
-					fmt.Println("3. fra1")
-					fmt.Println("4. blr1")
-					fmt.Println("5. sin1")
-					for {
-						regionNum, err := input.PromptForValue(reader, "Region number (1-5)", true)
-						if err != nil {
-							return err
-						}
-						regionMap := map[string]string{
-							"1": "default",
-							"2": "nyc1",
-							"3": "fra1",
-							"4": "blr1",
-							"5": "sin1",
-						}
-						if r, ok := regionMap[regionNum]; ok {
-							region = r
-							if valid, msg := validation.IsValidRegion(region); !valid {
-								fmt.Printf("Error: %s\n", msg)
-								continue
-							}
-							break
+				case !prompter.Interactive():
+					region = "default"
+				default:
+					fmt.Fprintln(os.Stderr, "Measuring latency to regions...")
+					fastest := ""
+					if nearest, ok := regions.Nearest(); ok {
+						fastest = nearest.Name
+					}
+					options := input.Options(regions.Names()...)
+					for i := range options {
+						if options[i].Value == fastest {
+							options[i].Hint = "fastest"
 						}
-						fmt.Println("Error: Invalid region selection")
+					}
+					region, err = prompter.Select("Region", options, fastest)
+					if err != nil {
+						return err
 					}
 				}
-			} else if valid, msg := validation.IsValidRegion(region); !valid {
-				return fmt.Errorf("invalid region: %s", msg)
 			}
 
 			// Optional comment
-			if comment == "" {
-				for {
-					comment, _ = input.PromptForValue(reader, "Comment (optional)", false)
-					if comment == "" {
-						break
-					}
-					if valid, msg := validation.IsValidComment(comment); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
+			if comment == "" && prompter.Interactive() {
+				comment, err = prompter.Text("Comment (optional)", "", validation.IsValidComment)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := spec().Validate().Rename(configFlags).Err(); err != nil {
+				return err
+			}
+
+			// Show what was put together before submitting it
+			if prompter.Asked() {
+				confirmed, err := prompter.Review("New configuration", []input.Field{
+					{Name: "Name", Value: name},
+					{Name: "Type", Value: configType},
+					{Name: "OpenVPN protocol", Value: openvpnProto},
+					{Name: "Region", Value: region},
+					{Name: "Comment", Value: comment},
+				})
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return fmt.Errorf("configuration not created")
 				}
-			} else if valid, msg := validation.IsValidComment(comment); !valid {
-				return fmt.Errorf("invalid comment: %s", msg)
 			}
 
 			format, err := output.ParseFormat(outputFormat)
@@ -322,6 +362,22 @@ func newCreateCommand() *cobra.Command {
 				Comment:      comment,
 			}
 
+			// Generate the keypair locally so only the public key is sent to the API
+			var privateKey string
+			if localKey {
+				if configType != "WireGuard" {
+					return fmt.Errorf("--local-key is only supported for WireGuard configurations")
+				}
+				privateKey, config.PublicKey, err = wireguard.GenerateKeyPair()
+				if err != nil {
+					return err
+				}
+			}
+
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return output.PrintDryRun("POST", "/configs", config, format)
+			}
+
 			result, err := client.CreateConfig(config)
 			if err != nil {
 				return err
@@ -331,7 +387,7 @@ func newCreateCommand() *cobra.Command {
 			var configID string
 			if response, ok := result.(map[string]interface{}); ok {
 				if data, ok := response["data"].(map[string]interface{}); ok {
-					if id := fmt.Sprintf("%v", data["id"]); id != "" && id != "<nil>" {
+					if id := api.FormatID(data["id"]); id != "" && id != "<nil>" {
 						configID = id
 					}
 				}
@@ -341,7 +397,7 @@ func newCreateCommand() *cobra.Command {
 				fmt.Println("waiting for the config file to be ready...")
 				time.Sleep(3 * time.Second)
 
-				baseURL := fmt.Sprintf("https://%s/api", common.GetDomainWithRegion(region))
+				baseURL := regions.APIURL(region)
 				client := api.NewClientWithBaseURL(token, baseURL)
 				// Fetch the full configuration with retries (3 attempts, 3 seconds apart)
 				response, err := fetchConfigWithRetry(client, configID, 2, 3*time.Second)
@@ -354,10 +410,11 @@ func newCreateCommand() *cobra.Command {
 					if configFile, exists := data["config_file"]; exists && configFile != "" {
 						opts := output.Options{
 							Format: format,
+							Kind:   output.KindConfig,
 						}
 
 						// Save config file with options
-						err := saveConfigFile(data, opts)
+						err := saveConfigFile(data, privateKey, opts)
 						if err != nil {
 							return fmt.Errorf("configuration created but failed to save config file: %w", err)
 						}
@@ -374,6 +431,7 @@ func newCreateCommand() *cobra.Command {
 			// If we couldn't save the config file, just return the creation result
 			opts := output.Options{
 				Format: format,
+				Kind:   output.KindConfig,
 			}
 			return output.Print(result, opts)
 		},
@@ -382,22 +440,28 @@ func newCreateCommand() *cobra.Command {
 	// Add flags
 	cmd.Flags().StringVar(&name, "name", "", "Configuration name")
 	cmd.Flags().StringVar(&configType, "type", "", "Configuration type (OpenVPN, SSH, WireGuard)")
+	cmd.RegisterFlagCompletionFunc("type", completion.ConfigTypes)
 	cmd.Flags().StringVar(&openvpnProto, "openvpn_proto", "", "OpenVPN protocol (tcp, udp), required for OpenVPN configurations")
-	cmd.Flags().StringVar(&region, "region", "", "Region (default, nyc1, fra1, blr1, sin1)")
+	cmd.RegisterFlagCompletionFunc("openvpn_proto", completion.OpenVPNProtocols)
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
 	cmd.Flags().StringVar(&comment, "comment", "", "Configuration comment")
+	cmd.Flags().BoolVar(&localKey, "local-key", false, "Generate the WireGuard keypair locally and send only the public key")
 
 	return cmd
 }
 
 func newShowCommand() *cobra.Command {
-	var isSaveConfigFile bool
+	var isSaveConfigFile, watch bool
 	var region string
+	var interval time.Duration
 
 	cmd := &cobra.Command{
-		Use:          "show [config-id]",
-		Short:        "Show configuration details",
-		Args:         cobra.ExactArgs(1),
-		SilenceUsage: true,
+		Use:               "show [config-id]",
+		ValidArgsFunction: completion.ConfigIDs,
+		Short:             "Show configuration details",
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
@@ -419,6 +483,9 @@ func newShowCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if watch && isSaveConfigFile {
+				return fmt.Errorf("--watch can't be combined with --save-config")
+			}
 
 			// First try with default or specified region
 			client := api.NewClient(token)
@@ -435,7 +502,7 @@ func newShowCommand() *cobra.Command {
 						// Get region from response
 						if respRegion, ok := data["region"].(string); ok && respRegion != "" && respRegion != "default" {
 							// Create new client with region-specific domain
-							baseURL := fmt.Sprintf("https://%s/api", common.GetDomainWithRegion(respRegion))
+							baseURL := regions.APIURL(respRegion)
 							client = api.NewClientWithBaseURL(token, baseURL)
 
 							// Retry with region-specific client
@@ -454,6 +521,13 @@ func newShowCommand() *cobra.Command {
 						}
 					}
 
+					if watch {
+						api.SetCacheTTL(0)
+						return output.Watch(cmd.CommandPath()+" "+args[0], interval, func() (interface{}, error) {
+							return client.GetConfig(args[0])
+						}, output.Options{Format: format, Kind: output.KindConfig})
+					}
+
 					result := map[string]interface{}{
 						"status": "success",
 						"data":   data,
@@ -465,7 +539,7 @@ func newShowCommand() *cobra.Command {
 							Format: format,
 						}
 
-						err := saveConfigFile(data, opts)
+						err := saveConfigFile(data, "", opts)
 						if err != nil {
 							return fmt.Errorf("failed to save config file: %w", err)
 						}
@@ -474,6 +548,7 @@ func newShowCommand() *cobra.Command {
 
 					opts := output.Options{
 						Format: format,
+						Kind:   output.KindConfig,
 					}
 					return output.Print(result, opts)
 				}
@@ -484,27 +559,40 @@ func newShowCommand() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&isSaveConfigFile, "save-config", false, "Save configuration file to disk")
-	cmd.Flags().StringVar(&region, "region", "", "Region (default, nyc1, fra1, blr1, sin1)")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Poll and redraw the configuration, highlighting changes (JSON and NDJSON print change events)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls with --watch")
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
 
 	return cmd
 }
 
 func newDeleteCommand() *cobra.Command {
+	var yes bool
+
 	cmd := &cobra.Command{
-		Use:          "delete [config-id]",
-		Short:        "Delete a configuration",
-		Args:         cobra.ExactArgs(1),
-		SilenceUsage: true,
+		Use:               "delete [config-id]",
+		ValidArgsFunction: completion.ConfigIDs,
+		Short:             "Delete a configuration",
+		Long:              "Delete a configuration and every mapping bound to it. The config and its mappings\nare listed and confirmation is asked for first, unless --yes is given.",
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
 
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
 			// Add validation before processing
 			if valid, msg := validation.IsValidID(args[0]); !valid {
 				return fmt.Errorf("invalid config ID: %s", msg)
 			}
 
-			// First get config details to determine region
+			// First get config details to determine region, as they are now
+			api.SetCacheTTL(0)
 			client := api.NewClient(token)
 			config, err := client.GetConfig(args[0])
 			if err != nil {
@@ -512,28 +600,51 @@ func newDeleteCommand() *cobra.Command {
 			}
 
 			// Extract region from response
+			var data map[string]interface{}
 			if response, ok := config.(map[string]interface{}); ok {
-				if data, ok := response["data"].(map[string]interface{}); ok {
+				if data, ok = response["data"].(map[string]interface{}); ok {
 					if region, ok := data["region"].(string); ok && region != "" && region != "default" {
 						// Create new client with region-specific domain
-						baseURL := fmt.Sprintf("https://%s/api", common.GetDomainWithRegion(region))
+						baseURL := regions.APIURL(region)
 						client = api.NewClientWithBaseURL(token, baseURL)
 					}
 				}
 			}
 
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return output.PrintDryRun("DELETE", "/configs/"+args[0], nil, format)
+			}
+
+			if !yes {
+				// The mappings go with the config, so show them before asking
+				mappings, err := client.ListMappings(map[string]string{"config_id": args[0]})
+				if err != nil {
+					return fmt.Errorf("failed to list mappings of config %s: %w", args[0], err)
+				}
+				describeDelete(os.Stderr, args[0], data, mappings)
+
+				confirmed, err := input.Confirm("Delete?")
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return fmt.Errorf("aborted")
+				}
+			}
+
 			// Delete the config using appropriate client
 			if err := client.DeleteConfig(args[0]); err != nil {
 				return err
 			}
 
-			if outputFormat == "text" {
+			if format == output.Text {
 				fmt.Println("Configuration deleted successfully")
 				return nil
 			}
 
 			opts := output.Options{
-				Format: output.JSON,
+				Format: format,
+				Kind:   output.KindStatus,
 			}
 			return output.Print(map[string]string{
 				"status":  "success",
@@ -542,5 +653,31 @@ func newDeleteCommand() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
 	return cmd
 }
+
+// describeDelete lists the config about to be deleted and the mappings bound to it
+func describeDelete(w io.Writer, configID string, config map[string]interface{}, mappings interface{}) {
+	var bound []map[string]interface{}
+	if response, ok := mappings.(map[string]interface{}); ok {
+		if data, ok := response["data"].([]interface{}); ok {
+			for _, item := range data {
+				if mapping, ok := item.(map[string]interface{}); ok {
+					bound = append(bound, mapping)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "Config %s %v (%v, %v) will be deleted", configID, config["name"], config["type"], config["region"])
+	if len(bound) == 0 {
+		fmt.Fprintln(w, ", it has no mappings.")
+		return
+	}
+	fmt.Fprintf(w, " along with %d mapping(s):\n", len(bound))
+	for _, m := range bound {
+		fmt.Fprintf(w, "  %s  %v://%v:%s -> %s\n", api.FormatID(m["id"]), m["protocol"], m["hostname"], api.FormatID(m["port_from"]), api.FormatID(m["port_to"]))
+	}
+}
@@ -55,12 +55,13 @@ func TestLiveConfigOperations(t *testing.T) {
 
 	// Add persistent flags
 	rootCmd.PersistentFlags().String("token", config.Token, "API token")
-	rootCmd.PersistentFlags().String("output", "json", "Output format")
+	rootCmd.PersistentFlags().String("output", "raw", "Output format")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 	rootCmd.PersistentFlags().String("env-file", "../../.env", "Path to env file")
 
 	// Set required flags
 	require.NoError(t, rootCmd.PersistentFlags().Set("token", config.Token))
-	require.NoError(t, rootCmd.PersistentFlags().Set("output", "json"))
+	require.NoError(t, rootCmd.PersistentFlags().Set("output", "raw"))
 	require.NoError(t, rootCmd.PersistentFlags().Set("env-file", "../../.env"))
 
 	// Test parameters
@@ -151,7 +152,7 @@ func TestLiveConfigOperations(t *testing.T) {
 	// t.Cleanup(func() {
 	// 	for _, conf := range createdConfigs {
 	// 		if conf.id != "" {
-	// 			_, _ = testutil.ExecuteCommand(rootCmd, "delete", conf.id)
+	// 			_, _ = testutil.ExecuteCommand(rootCmd, "delete", conf.id, "--yes")
 	// 		}
 	// 	}
 	// })
@@ -244,7 +245,7 @@ func TestLiveConfigOperations(t *testing.T) {
 	for _, conf := range createdConfigs {
 		apiDelay() // Add delay before each delete
 		t.Run(fmt.Sprintf("Delete_%s_%s", conf.confType, conf.id), func(t *testing.T) {
-			result, err := testutil.ExecuteCommand(rootCmd, "delete", conf.id)
+			result, err := testutil.ExecuteCommand(rootCmd, "delete", conf.id, "--yes")
 			require.NoError(t, err)
 			require.NotNil(t, result)
 			require.Equal(t, "success", result["status"])
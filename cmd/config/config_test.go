@@ -36,12 +36,22 @@ func (m *MockAPI) DeleteConfig(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockAPI) RotateConfigKey(id string, publicKey string) (interface{}, error) {
+	args := m.Called(id, publicKey)
+	return args.Get(0), args.Error(1)
+}
+
 // Add required mapping methods to satisfy the interface
 func (m *MockAPI) CreateMapping(req api.MappingRequest) (interface{}, error) {
 	args := m.Called(req)
 	return args.Get(0), args.Error(1)
 }
 
+func (m *MockAPI) UpdateMapping(id string, req api.MappingRequest) (interface{}, error) {
+	args := m.Called(id, req)
+	return args.Get(0), args.Error(1)
+}
+
 func (m *MockAPI) ListMappings(map[string]string) (interface{}, error) {
 	args := m.Called()
 	return args.Get(0), args.Error(1)
@@ -57,6 +67,16 @@ func (m *MockAPI) DeleteMapping(id string) error {
 	return args.Error(0)
 }
 
+func (m *MockAPI) GetAccount() (interface{}, error) {
+	args := m.Called()
+	return args.Get(0), args.Error(1)
+}
+
+func (m *MockAPI) ListRegions() (interface{}, error) {
+	args := m.Called()
+	return args.Get(0), args.Error(1)
+}
+
 
 
 func TestListCommand(t *testing.T) {
@@ -175,6 +195,7 @@ func TestListCommand(t *testing.T) {
 			cmd := NewCommand()
 			cmd.PersistentFlags().String("token", "test-token", "API token")
 			cmd.PersistentFlags().String("output", "json", "Output format")
+			cmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 
 			// Set mock API client
 			api.SetClient(mockAPI)
@@ -246,6 +267,7 @@ func TestCreateCommand(t *testing.T) {
 	cmd := NewCommand()
 	cmd.PersistentFlags().String("token", "test-token", "API token")
 	cmd.PersistentFlags().String("output", "json", "Output format")
+	cmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 
 	// Set mock API client
 	api.SetClient(mockAPI)
@@ -282,6 +304,7 @@ func TestShowCommand(t *testing.T) {
 	cmd := NewCommand()
 	cmd.PersistentFlags().String("token", "test-token", "API token")
 	cmd.PersistentFlags().String("output", "json", "Output format")
+	cmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 
 	// Set mock API client
 	api.SetClient(mockAPI)
@@ -295,16 +318,20 @@ func TestShowCommand(t *testing.T) {
 
 func TestDeleteCommand(t *testing.T) {
 	mockAPI := new(MockAPI)
+	mockAPI.On("GetConfig", "1").Return(map[string]interface{}{
+		"data": map[string]interface{}{"id": 1, "name": "test-config", "region": "default"},
+	}, nil)
 	mockAPI.On("DeleteConfig", "1").Return(nil)
 
 	cmd := NewCommand()
 	cmd.PersistentFlags().String("token", "test-token", "API token")
 	cmd.PersistentFlags().String("output", "json", "Output format")
+	cmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 
 	// Set mock API client
 	api.SetClient(mockAPI)
 
-	result, err := testutil.ExecuteCommand(cmd, "delete", "1")
+	result, err := testutil.ExecuteCommand(cmd, "delete", "1", "--yes")
 	require.NoError(t, err)
 
 	assert.Equal(t, "success", result["status"])
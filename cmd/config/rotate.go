@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/completion"
+	"portmap.io/client/internal/control"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/secret"
+	"portmap.io/client/internal/validation"
+	"portmap.io/client/internal/wireguard"
+)
+
+func newRotateKeyCommand() *cobra.Command {
+	var file string
+	var restart bool
+
+	cmd := &cobra.Command{
+		Use:               "rotate-key [config-id]",
+		ValidArgsFunction: completion.ConfigIDs,
+		Short:             "Rotate the key of a WireGuard configuration",
+		Long:              "Generate a new WireGuard keypair, register its public key with portmap.io and\nrewrite the private key of the local config file. Mappings bound to the config are kept.",
+		Args:              cobra.ExactArgs(1),
+		SilenceUsage:      true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := cmd.Flag("token").Value.String()
+			outputFormat := cmd.Flag("output").Value.String()
+			configID := args[0]
+
+			if valid, msg := validation.IsValidID(configID); !valid {
+				return fmt.Errorf("invalid config ID: %s", msg)
+			}
+
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			// Get config details to check its type and determine region, as they are now
+			api.SetCacheTTL(0)
+			client := api.NewClient(token)
+			config, err := client.GetConfig(configID)
+			if err != nil {
+				return err
+			}
+
+			var data map[string]interface{}
+			if response, ok := config.(map[string]interface{}); ok {
+				data, _ = response["data"].(map[string]interface{})
+			}
+			if data == nil {
+				return fmt.Errorf("invalid response format")
+			}
+			if configType, _ := data["type"].(string); configType != "WireGuard" {
+				return fmt.Errorf("config %s is a %s configuration, only WireGuard keys can be rotated", configID, configType)
+			}
+			if region, ok := data["region"].(string); ok && region != "" && region != "default" {
+				baseURL := regions.APIURL(region)
+				client = api.NewClientWithBaseURL(token, baseURL)
+			}
+
+			// The local config must exist, otherwise the new private key would have nowhere to go
+			if file == "" {
+				name, _ := data["name"].(string)
+				file = name + ".conf"
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read local config (use --file to point to it): %w", err)
+			}
+			if _, fileConfigID, err := wireguard.ParseConfigReader(bytes.NewReader(content)); err != nil {
+				return fmt.Errorf("invalid local config %s: %w", file, err)
+			} else if fileConfigID != configID {
+				return fmt.Errorf("local config %s belongs to config %s, not %s", file, fileConfigID, configID)
+			}
+
+			privateKey, publicKey, err := wireguard.GenerateKeyPair()
+			if err != nil {
+				return err
+			}
+
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return output.PrintDryRun("POST", "/configs/"+configID+"/rotate-key", map[string]string{"public_key": publicKey}, format)
+			}
+
+			// The new private key is saved before the API learns its public key, so
+			// the config never ends up with a key that exists nowhere. Keys kept in
+			// the secret store are staged there, plaintext ones in a copy of the file.
+			updated, oldKey := wireguard.SetPrivateKey(string(content), privateKey)
+			staged := file + ".new"
+			var store secret.Store
+			var key string
+			if secret.IsRef(oldKey) {
+				store, key, err = secret.OpenRef(oldKey)
+				if err == nil {
+					err = store.Set(key+".new", privateKey)
+				}
+			} else {
+				err = os.WriteFile(staged, []byte(updated), 0600)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save new private key, key not rotated: %w", err)
+			}
+
+			if _, err := client.RotateConfigKey(configID, publicKey); err != nil {
+				if store != nil {
+					store.Delete(key + ".new")
+				} else {
+					os.Remove(staged)
+				}
+				return fmt.Errorf("failed to register new key: %w", err)
+			}
+
+			// Then the staged key takes the place of the old one
+			if store != nil {
+				if err := store.Set(key, privateKey); err != nil {
+					return fmt.Errorf("key rotated but failed to store new private key, it is kept as %s.new in the %s store: %w", key, store.Name(), err)
+				}
+				store.Delete(key + ".new")
+			} else {
+				if secret.Configured() {
+					stored, err := wireguard.StorePrivateKey(updated, configID)
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to store new private key, %s keeps it in plaintext: %v\n", file, err)
+					} else {
+						updated = stored
+					}
+				}
+				if err := writeFileAtomic(file, []byte(updated)); err != nil {
+					return fmt.Errorf("key rotated but failed to save %s, the new config is in %s: %w", file, staged, err)
+				}
+				os.Remove(staged)
+			}
+
+			result := map[string]interface{}{
+				"status":     "success",
+				"message":    "Key rotated successfully",
+				"file":       file,
+				"public_key": publicKey,
+			}
+
+			if restart {
+				if _, err := control.Send(configID, control.Reload); err != nil {
+					return fmt.Errorf("key rotated but failed to restart connect: %w", err)
+				}
+				result["restarted"] = true
+			}
+
+			if format == output.Text {
+				fmt.Printf("✓ Key rotated, %s updated\n", file)
+				if restart {
+					fmt.Println("✓ Running connect session reloaded")
+				}
+				return nil
+			}
+
+			return output.Print(result, output.Options{Format: format, Kind: output.KindKeyRotation})
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Local config file to update (default: <config name>.conf)")
+	cmd.Flags().BoolVar(&restart, "restart", false, "Reload a running 'portmap connect' session with the new key")
+
+	return cmd
+}
+
+// writeFileAtomic replaces path with data so a failed write never leaves a truncated config behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
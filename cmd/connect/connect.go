@@ -1,47 +1,58 @@
 package connect
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/config"
+	"portmap.io/client/internal/control"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/secret"
 	"portmap.io/client/internal/wireguard"
 )
 
 func NewCommand() *cobra.Command {
 	var mgr *wireguard.Manager
 	var token string
-	var mappingRules []string
-	var serverHostname string
-	var localAddress string
 	var serviceMode bool
 
 	cmd := &cobra.Command{
-		Use:   "connect [config-file]",
+		Use:   "connect [config-file | -]",
 		Short: "Connect to WireGuard VPN",
+		Long: "Connect to WireGuard VPN using a config file, '-' to read the config from stdin,\n" +
+			"or the " + wireguard.ConfigEnvVar + " environment variable (raw or base64) when no file is given.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Enable VT processing at the start
 			enableVirtualTerminalProcessing()
 
-			if len(args) != 1 {
-				return fmt.Errorf("config file path required")
+			if len(args) > 1 {
+				return fmt.Errorf("only one config file can be given")
 			}
 
 			// Get token from root command
 			token = cmd.Flag("token").Value.String()
 
 			// Parse WireGuard config and extract portmap config_id
-			config, configID, err := wireguard.ParseConfig(args[0])
+			config, configID, err := loadConfig(args)
 			if err != nil {
 				return err
 			}
 
+			// The private key may be kept in the secret store
+			config.Interface.PrivateKey, err = secret.Resolve(config.Interface.PrivateKey)
+			if err != nil {
+				return fmt.Errorf("failed to read private key: %w", err)
+			}
+
 			// Fetch mappings for this config
 			client := api.NewClient(token)
 			params := map[string]string{
@@ -52,48 +63,10 @@ func NewCommand() *cobra.Command {
 				return err
 			}
 
-			// Store mapping rules for later display
-			if response, ok := mappings.(map[string]interface{}); ok {
-				// Extract region from the first mapping
-
-				if data, ok := response["data"].([]interface{}); ok && len(data) > 0 {
-					if mapping, ok := data[0].(map[string]interface{}); ok {
-
-						if config, ok := mapping["config"].(map[string]interface{}); ok {
-							region, _ := config["region"].(string)
-
-							// Format server hostname
-							serverHostname = "portmap.io"
-							if region != "" && region != "default" {
-								serverHostname = region + ".portmap.io"
-							}
-						}
-
-						// Get local address from WireGuard config (strip netmask)
-						localAddress = strings.Split(config.Interface.Address, "/")[0]
-
-						for _, item := range data {
-							if mapping, ok := item.(map[string]interface{}); ok {
-								hostname := mapping["hostname"].(string)
-								protocol := mapping["protocol"].(string)
-								portFrom := mapping["port_from"].(float64)
-								portTo := mapping["port_to"].(float64)
-								proxyToHTTP, _ := mapping["proxy_to_http"].(bool)
-
-								// Determine backend protocol
-								protocolTo := protocol
-								if protocol == "https" && proxyToHTTP {
-									protocolTo = "http"
-								}
-
-								mappingRules = append(mappingRules,
-									fmt.Sprintf("  • %s://%s:%0.f => %s://%s:%0.f",
-										protocol, hostname, portFrom, protocolTo, localAddress, portTo))
-							}
-						}
-					}
-				}
-			}
+			// Get local address from WireGuard config (strip netmask)
+			localAddress := strings.Split(config.Interface.Address, "/")[0]
+			rules, region := mappingRules(mappings, localAddress)
+			serverHostname := regions.Hostname(region)
 
 			// Setup WireGuard connection
 			mgr = wireguard.NewManager(config)
@@ -101,57 +74,53 @@ func NewCommand() *cobra.Command {
 				return err
 			}
 
-			// Print connection info and mapping rules
-			fmt.Printf("\n✓ Connected to %s via %s\n", serverHostname, mgr.GetInterfaceName())
-			if !serviceMode {
-				fmt.Printf("\nPress Ctrl+C to disconnect\n")
-				if len(mappingRules) > 0 {
-					fmt.Printf("\n✓ Available mapping rules:\n")
-					for _, rule := range mappingRules {
-						fmt.Println(rule)
-					}
-					fmt.Printf("\n↓ 0 B received\n")
-					fmt.Printf("↑ 0 B sent\n")
-				}
-			}
+			board := newDashboard(serverHostname, mgr.GetInterfaceName(), rules, time.Now())
 
-			// Setup signal handling and stats update
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-			ticker := time.NewTicker(1 * time.Second)
-			defer ticker.Stop()
-
-			go func() {
-				var lastRx, lastTx uint64
-				for {
-					select {
-					case <-sigChan:
-						if !serviceMode {
-							fmt.Printf("\n⚡ Disconnecting...\n")
-						}
-						if mgr != nil {
-							mgr.Cleanup()
-						}
-						os.Exit(0)
-					case <-ticker.C:
-						// Get traffic stats
-						if !serviceMode && len(mappingRules) > 0 {
-							rxBytes, txBytes := mgr.GetTrafficStats()
-							if rxBytes != lastRx || txBytes != lastTx {
-								// Clear previous lines and move cursor up
-								fmt.Print("\033[2F\033[J")
-								fmt.Printf("↓ %s received\n", humanize.Bytes(uint64(rxBytes)))
-								fmt.Printf("↑ %s sent\n", humanize.Bytes(uint64(txBytes)))
-								lastRx, lastTx = rxBytes, txBytes
-							}
-						}
+			// Let other commands (e.g. config rotate-key) reach this session
+			ctl, err := control.Listen(configID, func(command string) (string, error) {
+				switch command {
+				case control.Reload:
+					if len(args) == 0 || args[0] == "-" {
+						return "", fmt.Errorf("config was not read from a file and cannot be reloaded")
 					}
+					reloaded, _, err := wireguard.ParseConfig(args[0])
+					if err != nil {
+						return "", err
+					}
+					privateKey, err := secret.Refresh(reloaded.Interface.PrivateKey)
+					if err != nil {
+						return "", err
+					}
+					if err := mgr.SetPrivateKey(privateKey); err != nil {
+						return "", err
+					}
+					board.Log("Private key reloaded")
+					return "private key reloaded", nil
+				case control.Status:
+					return fmt.Sprintf("connected via %s", mgr.GetInterfaceName()), nil
+				default:
+					return "", fmt.Errorf("unknown command %s", command)
 				}
-			}()
-
-			// Keep running until signal
-			select {}
+			})
+			if err != nil {
+				mgr.Cleanup()
+				return err
+			}
+			defer mgr.Cleanup()
+			defer ctl.Close()
+
+			var v view
+			interactive := !serviceMode && term.IsTerminal(int(os.Stdout.Fd()))
+			if interactive {
+				v = &screenView{out: os.Stdout, d: board}
+			} else {
+				v = &logView{out: os.Stdout, d: board, quiet: serviceMode}
+			}
+			run(mgr, board, v, interactive)
+			if !serviceMode {
+				fmt.Printf("⚡ Disconnecting...\n")
+			}
+			return nil
 		},
 	}
 
@@ -160,3 +129,120 @@ func NewCommand() *cobra.Command {
 
 	return cmd
 }
+
+// mappingRules lists the mappings of a ListMappings response with the local
+// address they forward to, and returns the region of their config
+func mappingRules(mappings interface{}, localAddress string) ([]rule, string) {
+	var rules []rule
+	var region string
+	response, _ := mappings.(map[string]interface{})
+	data, _ := response["data"].([]interface{})
+	for _, item := range data {
+		mapping, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if config, ok := mapping["config"].(map[string]interface{}); ok && region == "" {
+			region, _ = config["region"].(string)
+		}
+
+		protocol, _ := mapping["protocol"].(string)
+		proxyToHTTP, _ := mapping["proxy_to_http"].(bool)
+
+		// Determine backend protocol
+		protocolTo := protocol
+		if protocol == "https" && proxyToHTTP {
+			protocolTo = "http"
+		}
+
+		rules = append(rules, rule{
+			URL:    fmt.Sprintf("%s://%v:%v", protocol, mapping["hostname"], mapping["port_from"]),
+			Target: fmt.Sprintf("%s://%s:%v", protocolTo, localAddress, mapping["port_to"]),
+		})
+	}
+	return rules, region
+}
+
+// run samples the connection every second and updates the view, until the
+// process is interrupted or q is pressed on the connect screen
+func run(mgr *wireguard.Manager, board *dashboard, v view, interactive bool) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	resized := make(chan os.Signal, 1)
+	keys := make(chan []byte)
+	if interactive {
+		notifyResize(resized)
+		defer signal.Stop(resized)
+
+		// Keys are read one press at a time, without echo
+		stdin := int(os.Stdin.Fd())
+		if state, err := term.MakeRaw(stdin); err == nil {
+			defer term.Restore(stdin, state)
+			go readKeys(os.Stdin, keys)
+		}
+	}
+
+	v.start()
+	defer v.stop()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	board.sample(mgr.Stats(), time.Now())
+	v.update(time.Now())
+	for {
+		select {
+		case <-sigChan:
+			return
+		case <-resized:
+			v.update(time.Now())
+		case key := <-keys:
+			quit, copied := board.key(key, time.Now())
+			if quit {
+				return
+			}
+			if copied != "" {
+				fmt.Print(clipboard(copied))
+			}
+			v.update(time.Now())
+		case now := <-ticker.C:
+			board.sample(mgr.Stats(), now)
+			v.update(now)
+		}
+	}
+}
+
+func readKeys(r io.Reader, keys chan<- []byte) {
+	buf := make([]byte, 16)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+		keys <- append([]byte(nil), buf[:n]...)
+	}
+}
+
+// loadConfig parses the WireGuard config from the file argument, stdin ("-")
+// or the PORTMAP_WG_CONFIG environment variable, in that order
+func loadConfig(args []string) (*config.WireguardConfig, string, error) {
+	if len(args) == 1 {
+		if args[0] == "-" {
+			return wireguard.ParseConfigReader(os.Stdin)
+		}
+		return wireguard.ParseConfig(args[0])
+	}
+
+	value := os.Getenv(wireguard.ConfigEnvVar)
+	if value == "" {
+		return nil, "", fmt.Errorf("config file path required (or use '-' for stdin, or set %s)", wireguard.ConfigEnvVar)
+	}
+
+	data, err := wireguard.DecodeConfig(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s: %w", wireguard.ConfigEnvVar, err)
+	}
+	return wireguard.ParseConfigReader(bytes.NewReader(data))
+}
@@ -0,0 +1,284 @@
+package connect
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dustin/go-humanize"
+	"portmap.io/client/internal/wireguard"
+)
+
+// staleAfter is how long without a handshake the connection counts as lost.
+// WireGuard re-handshakes every two minutes while traffic flows and gives up
+// on a session after three.
+const staleAfter = 3 * time.Minute
+
+// historySize is how many seconds of throughput the sparklines show at most
+const historySize = 120
+
+// rule is a mapping of the connected config, as shown on the connect screen
+type rule struct {
+	URL    string
+	Target string
+}
+
+func (r rule) String() string {
+	return fmt.Sprintf("%s => %s", r.URL, r.Target)
+}
+
+type event struct {
+	Time    time.Time
+	Message string
+}
+
+// dashboard is the state of a connect session: traffic, handshakes and what
+// happened so far. It is updated from the stats ticker and the control socket.
+type dashboard struct {
+	mu sync.Mutex
+
+	server  string
+	iface   string
+	rules   []rule
+	started time.Time
+
+	sampled    time.Time
+	rx, tx     uint64
+	rxRates    []float64
+	txRates    []float64
+	handshake  time.Time
+	connected  bool
+	reconnects int
+	events     []event
+
+	selected int
+	status   string
+	statusAt time.Time
+}
+
+func newDashboard(server, iface string, rules []rule, now time.Time) *dashboard {
+	d := &dashboard{server: server, iface: iface, rules: rules, started: now}
+	d.log(now, fmt.Sprintf("Connected to %s via %s", server, iface))
+	return d
+}
+
+func (d *dashboard) log(now time.Time, message string) {
+	d.events = append(d.events, event{Time: now, Message: message})
+}
+
+// Log records an event, such as a key reload, from another goroutine
+func (d *dashboard) Log(message string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log(time.Now(), message)
+}
+
+// sample adds the stats read at now: throughput since the previous sample, and
+// connection lost or regained when handshakes stop or resume
+func (d *dashboard) sample(stats wireguard.Stats, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.sampled.IsZero() {
+		seconds := now.Sub(d.sampled).Seconds()
+		if seconds > 0 {
+			d.rxRates = appendRate(d.rxRates, float64(stats.Rx-min(stats.Rx, d.rx))/seconds)
+			d.txRates = appendRate(d.txRates, float64(stats.Tx-min(stats.Tx, d.tx))/seconds)
+		}
+	}
+	d.sampled, d.rx, d.tx = now, stats.Rx, stats.Tx
+
+	if stats.LastHandshake.After(d.handshake) {
+		switch {
+		case d.handshake.IsZero():
+			d.log(now, fmt.Sprintf("Handshake with %s completed", d.server))
+		case !d.connected:
+			d.reconnects++
+			d.log(now, "Reconnected")
+		}
+		d.handshake, d.connected = stats.LastHandshake, true
+	}
+	if d.connected && now.Sub(d.handshake) > staleAfter {
+		d.connected = false
+		d.log(now, fmt.Sprintf("Connection lost: no handshake for %s", staleAfter))
+	}
+}
+
+func appendRate(rates []float64, rate float64) []float64 {
+	rates = append(rates, rate)
+	if len(rates) > historySize {
+		rates = rates[len(rates)-historySize:]
+	}
+	return rates
+}
+
+// key handles a key press of the connect screen. It returns whether to quit,
+// and text to copy to the clipboard.
+func (d *dashboard) key(b []byte, now time.Time) (quit bool, copied string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch k := string(b); {
+	case k == "q" || k == "\x03":
+		return true, ""
+	case k == "\x1b[A" || k == "k":
+		if d.selected > 0 {
+			d.selected--
+		}
+	case k == "\x1b[B" || k == "j":
+		if d.selected < len(d.rules)-1 {
+			d.selected++
+		}
+	case k == "c" || k == "\r" || k == "\n":
+		copied = d.copy(d.selected, now)
+	case len(k) == 1 && k[0] >= '1' && k[0] <= '9':
+		copied = d.copy(int(k[0]-'1'), now)
+	}
+	return false, copied
+}
+
+func (d *dashboard) copy(i int, now time.Time) string {
+	if i < 0 || i >= len(d.rules) {
+		return ""
+	}
+	d.selected = i
+	d.status, d.statusAt = "Copied "+d.rules[i].URL, now
+	return d.rules[i].URL
+}
+
+// clipboard is the OSC 52 sequence most terminals, also over SSH, answer by
+// setting the clipboard to text
+func clipboard(text string) string {
+	return "\033]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+}
+
+// render lays the dashboard out for a terminal of width by height
+func (d *dashboard) render(width, height int, now time.Time) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := []string{
+		fmt.Sprintf(" portmap connect · %s via %s · up %s · reconnects %d",
+			d.server, d.iface, duration(now.Sub(d.started)), d.reconnects),
+		" " + d.handshakeStatus(now),
+		"",
+	}
+
+	if len(d.rules) > 0 {
+		lines = append(lines, " MAPPINGS")
+		for i, r := range d.rules {
+			cursor := "  "
+			if i == d.selected {
+				cursor = "> "
+			}
+			lines = append(lines, fmt.Sprintf(" %s%d %s", cursor, i+1, r))
+		}
+		lines = append(lines, "")
+	}
+
+	// Sparklines take what is left of the line after the rate and total
+	graph := width - 38
+	lines = append(lines,
+		fmt.Sprintf(" ↓ %-11s %s  %s received", rate(last(d.rxRates)), sparkline(d.rxRates, graph), humanize.Bytes(d.rx)),
+		fmt.Sprintf(" ↑ %-11s %s  %s sent", rate(last(d.txRates)), sparkline(d.txRates, graph), humanize.Bytes(d.tx)),
+		"",
+		" EVENTS",
+	)
+
+	footer := " ↑/↓ select  c copy URL  1-9 copy URL n  q quit"
+	if d.status != "" && now.Sub(d.statusAt) < 3*time.Second {
+		footer += "  · " + d.status
+	}
+
+	// The latest events that fit above the footer
+	room := height - len(lines) - 2
+	events := d.events
+	if room < 0 {
+		room = 0
+	}
+	if len(events) > room {
+		events = events[len(events)-room:]
+	}
+	for _, e := range events {
+		lines = append(lines, fmt.Sprintf(" %s  %s", e.Time.Format("15:04:05"), e.Message))
+	}
+	// The footer stays at the bottom, however small the terminal
+	if len(lines) > height-1 {
+		lines = lines[:max(height-1, 0)]
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	lines = append(lines, footer)
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	return lines
+}
+
+func (d *dashboard) handshakeStatus(now time.Time) string {
+	if d.handshake.IsZero() {
+		return "Waiting for the first handshake"
+	}
+	status := fmt.Sprintf("Last handshake %s ago", duration(now.Sub(d.handshake)))
+	if !d.connected {
+		status += " · connection lost"
+	}
+	return status
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width rates, scaled to the largest of them
+func sparkline(rates []float64, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if len(rates) > width {
+		rates = rates[len(rates)-width:]
+	}
+	var highest float64
+	for _, r := range rates {
+		highest = max(highest, r)
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", width-len(rates)))
+	for _, r := range rates {
+		level := 0
+		if highest > 0 {
+			level = int(r / highest * float64(len(sparks)-1))
+		}
+		b.WriteRune(sparks[level])
+	}
+	return b.String()
+}
+
+func last(rates []float64) float64 {
+	if len(rates) == 0 {
+		return 0
+	}
+	return rates[len(rates)-1]
+}
+
+func rate(bytesPerSecond float64) string {
+	return humanize.Bytes(uint64(bytesPerSecond)) + "/s"
+}
+
+// duration formats d in whole seconds
+func duration(d time.Duration) string {
+	return d.Truncate(time.Second).String()
+}
+
+func truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
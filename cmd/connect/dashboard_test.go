@@ -0,0 +1,92 @@
+package connect
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"portmap.io/client/internal/wireguard"
+)
+
+func TestMappingRules(t *testing.T) {
+	rules, region := mappingRules(map[string]interface{}{"data": []interface{}{
+		map[string]interface{}{"hostname": "app.portmap.io", "protocol": "https", "port_from": float64(443),
+			"port_to": float64(8080), "proxy_to_http": true, "config": map[string]interface{}{"region": "fra1"}},
+		map[string]interface{}{"hostname": "ssh.portmap.io", "protocol": "tcp", "port_from": float64(2222), "port_to": "22"},
+	}}, "10.9.0.2")
+
+	assert.Equal(t, "fra1", region)
+	assert.Equal(t, []rule{
+		{URL: "https://app.portmap.io:443", Target: "http://10.9.0.2:8080"},
+		{URL: "tcp://ssh.portmap.io:2222", Target: "tcp://10.9.0.2:22"},
+	}, rules)
+}
+
+func TestDashboardReconnects(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	d := newDashboard("fra1.portmap.io", "wg0", nil, start)
+
+	d.sample(wireguard.Stats{}, start)
+	d.sample(wireguard.Stats{Rx: 2048, LastHandshake: start}, start.Add(2*time.Second))
+	assert.Equal(t, []float64{1024}, d.rxRates)
+
+	d.sample(wireguard.Stats{Rx: 2048, LastHandshake: start}, start.Add(4*time.Minute))
+	d.sample(wireguard.Stats{Rx: 4096, LastHandshake: start.Add(5 * time.Minute)}, start.Add(5*time.Minute))
+
+	var messages []string
+	for _, e := range d.events {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{
+		"Connected to fra1.portmap.io via wg0",
+		"Handshake with fra1.portmap.io completed",
+		"Connection lost: no handshake for 3m0s",
+		"Reconnected",
+	}, messages)
+	assert.Equal(t, 1, d.reconnects)
+}
+
+func TestDashboardKeys(t *testing.T) {
+	now := time.Now()
+	d := newDashboard("portmap.io", "wg0", []rule{{URL: "tcp://a.portmap.io:1"}, {URL: "tcp://b.portmap.io:2"}}, now)
+
+	quit, copied := d.key([]byte("\x1b[B"), now)
+	assert.False(t, quit)
+	assert.Empty(t, copied)
+	_, copied = d.key([]byte("c"), now)
+	assert.Equal(t, "tcp://b.portmap.io:2", copied)
+	_, copied = d.key([]byte("1"), now)
+	assert.Equal(t, "tcp://a.portmap.io:1", copied)
+	_, copied = d.key([]byte("9"), now)
+	assert.Empty(t, copied)
+
+	quit, _ = d.key([]byte("q"), now)
+	assert.True(t, quit)
+	assert.Equal(t, "\033]52;c;dGNwOi8vYS5wb3J0bWFwLmlvOjE=\a", clipboard("tcp://a.portmap.io:1"))
+}
+
+func TestDashboardRenderFits(t *testing.T) {
+	now := time.Now()
+	d := newDashboard("portmap.io", "wg0", []rule{{URL: "https://a.portmap.io:443", Target: "http://10.9.0.2:8080"}}, now)
+	for i := 0; i < 50; i++ {
+		d.log(now, "event")
+	}
+	for _, size := range [][2]int{{80, 24}, {30, 10}, {200, 5}} {
+		lines := d.render(size[0], size[1], now)
+		require.Len(t, lines, size[1])
+		for _, line := range lines {
+			assert.LessOrEqual(t, utf8.RuneCountInString(line), size[0])
+		}
+		assert.True(t, strings.HasPrefix(lines[len(lines)-1], " ↑/↓ select"))
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "  ▁▄█", sparkline([]float64{0, 50, 100}, 5))
+	assert.Equal(t, "▄█", sparkline([]float64{0, 50, 100}, 2))
+	assert.Equal(t, "▁▁", sparkline([]float64{0, 0}, 2))
+	assert.Empty(t, sparkline([]float64{1}, 0))
+}
@@ -2,6 +2,17 @@
 
 package connect
 
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
 func enableVirtualTerminalProcessing() {
-    // Not needed on Unix systems as they support ANSI escape sequences by default
+	// Not needed on Unix systems as they support ANSI escape sequences by default
+}
+
+// notifyResize sends to c when the terminal is resized
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
 }
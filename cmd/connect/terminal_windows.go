@@ -3,13 +3,18 @@
 package connect
 
 import (
-    "os"
-    "golang.org/x/sys/windows"
+	"os"
+
+	"golang.org/x/sys/windows"
 )
 
 func enableVirtualTerminalProcessing() {
-    stdout := windows.Handle(os.Stdout.Fd())
-    var mode uint32
-    windows.GetConsoleMode(stdout, &mode)
-    windows.SetConsoleMode(stdout, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+	stdout := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	windows.GetConsoleMode(stdout, &mode)
+	windows.SetConsoleMode(stdout, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
 }
+
+// notifyResize does nothing: Windows has no resize signal, the screen picks
+// up the new size on its next redraw
+func notifyResize(c chan<- os.Signal) {}
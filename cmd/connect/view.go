@@ -0,0 +1,99 @@
+package connect
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"golang.org/x/term"
+)
+
+// view shows a dashboard as it changes
+type view interface {
+	start()
+	update(now time.Time)
+	stop()
+}
+
+// screenView draws the dashboard full screen, in the alternate screen of the
+// terminal so the shell is left as it was on exit
+type screenView struct {
+	out *os.File
+	d   *dashboard
+}
+
+func (v *screenView) start() {
+	// Alternate screen, cursor hidden
+	fmt.Fprint(v.out, "\033[?1049h\033[?25l")
+}
+
+func (v *screenView) update(now time.Time) {
+	width, height, err := term.GetSize(int(v.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+
+	var frame strings.Builder
+	frame.WriteString("\033[H")
+	for i, line := range v.d.render(width, height, now) {
+		if i > 0 {
+			// The terminal may be in raw mode, which doesn't turn \n into \r\n
+			frame.WriteString("\r\n")
+		}
+		frame.WriteString(line)
+		frame.WriteString("\033[K")
+	}
+	frame.WriteString("\033[J")
+	io.WriteString(v.out, frame.String())
+}
+
+func (v *screenView) stop() {
+	fmt.Fprint(v.out, "\033[?25h\033[?1049l")
+}
+
+// logView prints events as log lines, for output that isn't a terminal.
+// Unless quiet, the mappings are listed at the start and the traffic every minute.
+type logView struct {
+	out     io.Writer
+	d       *dashboard
+	quiet   bool
+	printed int
+	traffic time.Time
+}
+
+const trafficEvery = time.Minute
+
+func (v *logView) start() {
+	v.traffic = time.Now()
+	if v.quiet {
+		return
+	}
+	for _, r := range v.d.rules {
+		v.print(v.traffic, "Mapping "+r.String())
+	}
+}
+
+func (v *logView) update(now time.Time) {
+	v.d.mu.Lock()
+	events := v.d.events[v.printed:]
+	v.printed = len(v.d.events)
+	rx, tx := v.d.rx, v.d.tx
+	v.d.mu.Unlock()
+
+	for _, e := range events {
+		v.print(e.Time, e.Message)
+	}
+	if !v.quiet && now.Sub(v.traffic) >= trafficEvery {
+		v.print(now, fmt.Sprintf("Traffic: %s received, %s sent", humanize.Bytes(rx), humanize.Bytes(tx)))
+		v.traffic = now
+	}
+}
+
+func (v *logView) stop() {}
+
+func (v *logView) print(t time.Time, message string) {
+	fmt.Fprintf(v.out, "%s %s\n", t.Format(time.RFC3339), message)
+}
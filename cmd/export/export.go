@@ -0,0 +1,79 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/state"
+)
+
+func NewCommand() *cobra.Command {
+	var file, format string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every config and mapping of the account to a file",
+		Long: "Write every config and mapping of the account to a versioned YAML or JSON document,\n" +
+			"for backups or to restore and clone them with 'portmap import'. The document is\n" +
+			"also valid input for 'portmap apply'.",
+		Example: "  portmap export -f backup.yaml\n" +
+			"  portmap export --format json > backup.json",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := cmd.Flag("token").Value.String()
+
+			// The file extension picks the format unless it is given
+			if format == "" {
+				format = "yaml"
+				if strings.EqualFold(filepath.Ext(file), ".json") {
+					format = "json"
+				}
+			}
+			format = strings.ToLower(format)
+			if format != "yaml" && format != "json" {
+				return fmt.Errorf("invalid format: %s (supported: yaml, json)", format)
+			}
+
+			doc, err := state.Fetch(api.NewClient(token))
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			toFile := file != "" && file != "-"
+			if toFile {
+				f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := state.Encode(w, doc, format); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+
+			if toFile {
+				mappings := 0
+				for _, c := range doc.Configs {
+					mappings += len(c.Mappings)
+				}
+				fmt.Fprintf(os.Stderr, "✓ Exported %d config(s) and %d mapping(s) to %s\n", len(doc.Configs), mappings, file)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "filename", "f", "", "File to write (default: stdout)")
+	cmd.Flags().StringVar(&format, "format", "", "Document format (yaml, json), default from the file extension, else yaml")
+	cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
+
+	return cmd
+}
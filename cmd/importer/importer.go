@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/completion"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/state"
+	"portmap.io/client/internal/validation"
+)
+
+func NewCommand() *cobra.Command {
+	var file, region string
+
+	cmd := &cobra.Command{
+		Use:   "import -f backup.yaml",
+		Short: "Recreate configs and mappings from an export",
+		Long: "Recreate the configs and mappings of a 'portmap export' document. New configs get new\n" +
+			"IDs and their mappings are bound to them. Configs that already exist by name and region\n" +
+			"are reused and mappings that already exist are skipped, so an import can be repeated.\n" +
+			"A config whose name is used in another region is created as <name>-<region>.",
+		Example: "  portmap import -f backup.yaml\n" +
+			"  portmap import -f backup.yaml --region nyc1 --dry-run",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := cmd.Flag("token").Value.String()
+			outputFormat := cmd.Flag("output").Value.String()
+
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			doc, err := state.LoadFile(file)
+			if err != nil {
+				return err
+			}
+
+			// Cloning into another region moves every config there
+			if region != "" {
+				if valid, msg := validation.IsValidRegion(region); !valid {
+					return fmt.Errorf("invalid region: %s", msg)
+				}
+				for i := range doc.Configs {
+					doc.Configs[i].Region = region
+				}
+			}
+			if err := state.Validate(doc); err != nil {
+				return fmt.Errorf("invalid %s: %w", file, err)
+			}
+
+			// The plan is made against the account as it is now
+			api.SetCacheTTL(0)
+			current, err := state.Fetch(api.NewClient(token))
+			if err != nil {
+				return err
+			}
+
+			plan, notes := state.Merge(doc, current)
+
+			// Merge may have renamed configs, so look their old IDs up by the new names
+			oldIDs := make(map[string]string)
+			for _, c := range doc.Configs {
+				oldIDs[c.Name] = c.ID
+			}
+			if format == output.Text {
+				for _, note := range notes {
+					fmt.Printf("  %s\n", note)
+				}
+				for _, a := range plan.Actions {
+					fmt.Println(a)
+				}
+				fmt.Println(plan.Summary())
+			}
+			dryRun := cmd.Flag("dry-run").Value.String() == "true"
+			if dryRun || len(plan.Actions) == 0 {
+				if format == output.Text {
+					return nil
+				}
+				return output.Print(map[string]interface{}{
+					"status":  "success",
+					"dry_run": dryRun,
+					"actions": plan.Actions,
+					"skipped": notes,
+				}, output.Options{Format: format, Kind: output.KindPlan})
+			}
+
+			// Old config IDs of the document and the ones they became
+			remapped := make(map[string]string)
+			clientFor := func(region string) api.Client {
+				if region == "" || region == "default" {
+					return api.NewClient(token)
+				}
+				return api.NewClientWithBaseURL(token, regions.APIURL(region))
+			}
+			err = plan.Apply(clientFor, func(a state.Action) {
+				if a.Mapping == nil && oldIDs[a.Config.Name] != "" {
+					remapped[oldIDs[a.Config.Name]] = a.Config.ID
+				}
+				if format == output.Text {
+					fmt.Printf("✓ %s\n", a)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			if format == output.Text {
+				for oldID, newID := range remapped {
+					fmt.Printf("  config %s is now %s\n", oldID, newID)
+				}
+				fmt.Println("Import complete")
+				return nil
+			}
+			return output.Print(map[string]interface{}{
+				"status":     "success",
+				"message":    "Import complete",
+				"actions":    plan.Actions,
+				"skipped":    notes,
+				"config_ids": remapped,
+			}, output.Options{Format: format, Kind: output.KindPlan})
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "filename", "f", "", "Export document to import, or - for stdin")
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Create every config in this region instead"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
+	cmd.MarkFlagRequired("filename")
+	cmd.MarkFlagFilename("filename", "yaml", "yml", "json")
+
+	return cmd
+}
@@ -1,77 +1,317 @@
 package initialize
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/completion"
+	"portmap.io/client/internal/input"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/secret"
+	"portmap.io/client/internal/validation"
+	"portmap.io/client/internal/wireguard"
 	"portmap.io/client/pkg/config"
 )
 
 func NewCommand() *cobra.Command {
+	var useKeyring, yes bool
+	var token, format, region string
+
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize portmap.io client configuration",
+		Example: "  portmap init\n" +
+			"  portmap init --token \"$PORTMAP_TOKEN\" --format json --region fra1 --yes",
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Print("Enter your Portmap.io API token: ")
-			var token string
-			if _, err := fmt.Scanln(&token); err != nil {
+			envFile := cmd.Flag("env-file").Value.String()
+			reader := bufio.NewReader(os.Stdin)
+
+			existing, err := config.LoadRawConfig(envFile)
+			if err != nil {
 				return err
 			}
 
-			var format string
-			for {
-				fmt.Print("Choose default output format (json/text) [text]: ")
-				fmt.Scanln(&format)
-				format = strings.ToLower(format)
-				if format == "" {
-					format = "text"
+			var store secret.Store
+			if useKeyring {
+				if existing.SecretBackend == "" {
+					os.Setenv(secret.BackendEnvVar, "auto")
 				}
-				if format == "json" || format == "text" {
-					break
+				store, err = secret.Open()
+				if err != nil {
+					return err
 				}
-				fmt.Println("Invalid format. Please enter 'json' or 'text'")
-			}
-
-			var region string
-			for {
-				fmt.Println("\nSelect default region:")
-				fmt.Println("1. default")
-				fmt.Println("2. nyc1")
-				fmt.Println("3. fra1")
-				fmt.Println("4. blr1")
-                fmt.Println("5. sin1")
-				fmt.Print("Enter region number (1-5) [1]: ")
-
-				var choice string
-				fmt.Scanln(&choice)
-				if choice == "" {
-					choice = "1"
+
+				// An existing plaintext setup only needs its secrets moved
+				if existing.Token != "" && !secret.IsRef(existing.Token) && token == "" {
+					return migrate(store, existing)
 				}
+			}
 
-				regionMap := map[string]string{
-					"1": "default",
-					"2": "nyc1",
-					"3": "fra1",
-					"4": "blr1",
-                    "5": "sin1",
+			// Flags are checked before anything is asked, so all mistakes are
+			// reported at once. Without input, a missing token is one too.
+			interactive := input.Interactive()
+			var problems validation.Errors
+			if token == "" && !interactive && input.IsTerminal() {
+				problems.Add("--token", validation.Required)
+			}
+			if f := strings.ToLower(format); f != "" && f != "json" && f != "text" {
+				problems.Add("--format", "Format must be one of: json, text")
+			}
+			if valid, msg := validation.IsValidRegion(region); region != "" && !valid {
+				problems.Add("--region", msg)
+			}
+			if err := problems.Err(); err != nil {
+				return err
+			}
+
+			// Don't replace a working setup by accident
+			if existing.Token != "" && !yes {
+				if !interactive {
+					return fmt.Errorf("client is already configured, use --yes to overwrite")
+				}
+				answer, err := input.PromptForValue(reader, "Client is already configured. Overwrite? (y/N)", false)
+				if err != nil {
+					return err
 				}
+				if strings.ToLower(answer) != "y" {
+					return fmt.Errorf("aborted")
+				}
+			}
 
-				if selectedRegion, ok := regionMap[choice]; ok {
-					region = selectedRegion
-					break
+			if token == "" {
+				// Keep the token off the screen when typed, but allow piping it in
+				if interactive {
+					token, err = input.ReadSecret("Enter your Portmap.io API token")
+				} else {
+					token, err = reader.ReadString('\n')
+					if err == io.EOF && token != "" {
+						err = nil
+					}
 				}
-				fmt.Println("Invalid selection. Please enter a number between 1 and 4")
+				if err != nil {
+					return fmt.Errorf("failed to read token: %w", err)
+				}
+			}
+			token = strings.TrimSpace(token)
+			if token == "" {
+				return fmt.Errorf("API token cannot be empty")
 			}
 
-			if err := config.SaveConfig(token, format, region); err != nil {
+			if format == "" && !yes && interactive {
+				for {
+					format, err = input.PromptForValue(reader, "Choose default output format (json/text) [text]", false)
+					if err != nil {
+						return err
+					}
+					format = strings.ToLower(format)
+					if format == "" || format == "json" || format == "text" {
+						break
+					}
+					fmt.Println("Invalid format. Please enter 'json' or 'text'")
+				}
+			}
+			format = strings.ToLower(format)
+			if format == "" {
+				format = "text"
+			}
+			if format != "json" && format != "text" {
+				return fmt.Errorf("invalid format: %s (supported: json, text)", format)
+			}
+
+			if region == "" && !yes && interactive {
+				names := regions.Names()
+				fastest := nearestChoice(names)
+				defaultChoice := fastest
+				if defaultChoice == 0 {
+					defaultChoice = 1
+				}
+				for {
+					fmt.Println("\nSelect default region:")
+					for i, name := range names {
+						if i+1 == fastest {
+							fmt.Printf("%d. %s (fastest)\n", i+1, name)
+						} else {
+							fmt.Printf("%d. %s\n", i+1, name)
+						}
+					}
+
+					choice, err := input.PromptForValue(reader, fmt.Sprintf("Enter region number (1-%d) [%d]", len(names), defaultChoice), false)
+					if err != nil {
+						return err
+					}
+					if choice == "" {
+						choice = fmt.Sprint(defaultChoice)
+					}
+
+					var n int
+					if _, err := fmt.Sscanf(choice, "%d", &n); err == nil && n >= 1 && n <= len(names) {
+						region = names[n-1]
+						break
+					}
+					fmt.Printf("Invalid selection. Please enter a number between 1 and %d\n", len(names))
+				}
+			}
+			if region == "" {
+				region = "default"
+			}
+			if valid, msg := validation.IsValidRegion(region); !valid {
+				return fmt.Errorf("invalid region: %s", msg)
+			}
+
+			// Check the token before saving it, so a typo doesn't surface on the next command
+			account, err := verifyToken(token, existing.APIURL)
+			if err != nil {
+				return fmt.Errorf("token check failed: %w", err)
+			}
+
+			// New settings go to the profile unless a .env file was asked for explicitly
+			cfg := &config.Config{
+				Token:         token,
+				OutputFormat:  format,
+				Region:        region,
+				APIURL:        existing.APIURL,
+				SecretBackend: existing.SecretBackend,
+				Profile:       existing.Profile,
+				EnvFile:       envFile,
+			}
+			if store != nil {
+				key := tokenSecretKey(cfg)
+				if err := store.Set(key, token); err != nil {
+					return err
+				}
+				cfg.Token = secret.Ref(store, key)
+				cfg.SecretBackend = store.Name()
+			}
+
+			if err := config.SaveConfig(cfg); err != nil {
 				return err
 			}
 
-			fmt.Printf("Configuration saved successfully!\n")
+			fmt.Printf("✓ Authenticated as %s\n", account)
+			printSaved(cfg)
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&token, "token", "", "API token (prompted for, or read from stdin, when omitted)")
+	cmd.Flags().StringVar(&format, "format", "", "Default output format (json, text)")
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Default region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't prompt: use defaults for omitted options and overwrite an existing configuration")
+	cmd.Flags().BoolVar(&useKeyring, "keyring", false, "Store the API token and WireGuard private keys in the OS keyring (or an encrypted file) and migrate existing plaintext secrets")
+
 	return cmd
 }
+
+// verifyToken calls the API with token and returns a description of the account it belongs to
+func verifyToken(token, apiURL string) (string, error) {
+	if apiURL == "" {
+		apiURL = api.DefaultBaseURL
+	}
+
+	client := api.NewClientWithBaseURL(token, apiURL)
+	account, err := client.GetAccount()
+	if err != nil {
+		return "", err
+	}
+
+	if response, ok := account.(map[string]interface{}); ok {
+		if data, ok := response["data"].(map[string]interface{}); ok {
+			for _, key := range []string{"email", "username", "login", "name"} {
+				if value, ok := data[key].(string); ok && value != "" {
+					return value, nil
+				}
+			}
+		}
+	}
+	return "token owner", nil
+}
+
+// nearestChoice probes the regions and returns the menu number of the fastest, or 0 when none answers
+func nearestChoice(names []string) int {
+	fmt.Println("\nMeasuring latency to regions...")
+	nearest, ok := regions.Nearest()
+	if !ok {
+		return 0
+	}
+	for i, name := range names {
+		if name == nearest.Name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// tokenSecretKey returns the secret store key of the API token; every profile has its own
+func tokenSecretKey(cfg *config.Config) string {
+	if cfg.EnvFile != "" || cfg.Profile == "" {
+		return "token"
+	}
+	return "token/" + cfg.Profile
+}
+
+func printSaved(cfg *config.Config) {
+	if cfg.EnvFile != "" {
+		fmt.Printf("Configuration saved successfully to %s!\n", cfg.EnvFile)
+		return
+	}
+	path, _ := config.Path()
+	fmt.Printf("Configuration saved successfully to profile %q in %s!\n", cfg.Profile, path)
+}
+
+// migrate moves the plaintext token and the private keys of WireGuard configs
+// in the current directory into store, saving the config back where it came from
+func migrate(store secret.Store, cfg *config.Config) error {
+	key := tokenSecretKey(cfg)
+	if err := store.Set(key, cfg.Token); err != nil {
+		return err
+	}
+	cfg.Token = secret.Ref(store, key)
+	cfg.SecretBackend = store.Name()
+	os.Setenv(secret.BackendEnvVar, store.Name())
+
+	if err := config.SaveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("✓ API token moved to %s store\n", store.Name())
+
+	files, err := filepath.Glob("*.conf")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		// Only portmap WireGuard configs carry a config_id to key the secret by
+		_, configID, err := wireguard.ParseConfigReader(bytes.NewReader(content))
+		if err != nil {
+			continue
+		}
+
+		updated, err := wireguard.StorePrivateKey(string(content), configID)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", file, err)
+		}
+		if updated == string(content) {
+			continue
+		}
+		if err := os.WriteFile(file, []byte(updated), 0600); err != nil {
+			return fmt.Errorf("failed to save %s: %w", file, err)
+		}
+		fmt.Printf("✓ Private key of %s moved to %s store\n", file, store.Name())
+	}
+
+	printSaved(cfg)
+	return nil
+}
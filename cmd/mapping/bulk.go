@@ -0,0 +1,302 @@
+package mapping
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/input"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/internal/validation"
+)
+
+// bulkFilter selects the mappings of a bulk delete
+type bulkFilter struct {
+	configID string
+	protocol string
+	hostname string
+}
+
+func (f bulkFilter) empty() bool {
+	return f.configID == "" && f.protocol == "" && f.hostname == ""
+}
+
+func (f bulkFilter) validate() error {
+	if f.configID != "" {
+		if valid, msg := validation.IsValidID(f.configID); !valid {
+			return fmt.Errorf("invalid config ID: %s", msg)
+		}
+	}
+	if f.protocol != "" {
+		if valid, msg := validation.IsValidProtocol(f.protocol); !valid {
+			return fmt.Errorf("invalid protocol: %s", msg)
+		}
+	}
+	if _, err := path.Match(f.hostname, ""); err != nil {
+		return fmt.Errorf("invalid hostname pattern: %s", f.hostname)
+	}
+	return nil
+}
+
+// bulkTarget is a mapping to delete. Mappings given by ID are looked up while
+// deleting, so their description and region start out empty.
+type bulkTarget struct {
+	id          string
+	description string
+	region      string
+}
+
+type bulkResult struct {
+	ID      string `json:"id"`
+	Mapping string `json:"mapping,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+func runBulkDelete(cmd *cobra.Command, args []string, filter bulkFilter, yes bool, parallel int) error {
+	token := cmd.Flag("token").Value.String()
+	outputFormat := cmd.Flag("output").Value.String()
+	dryRun := cmd.Flag("dry-run").Value.String() == "true"
+
+	// What gets deleted is decided on the mappings as they are now
+	api.SetCacheTTL(0)
+
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		return fmt.Errorf("--parallel must be at least 1")
+	}
+
+	var targets []bulkTarget
+	if len(args) == 1 {
+		if !filter.empty() {
+			return fmt.Errorf("IDs from stdin can't be combined with --config-id, --protocol or --hostname")
+		}
+		targets, err = readTargets(os.Stdin)
+	} else {
+		if filter.empty() {
+			return fmt.Errorf("specify a mapping ID, - to read IDs from stdin, or at least one of --config-id, --protocol and --hostname")
+		}
+		if err := filter.validate(); err != nil {
+			return err
+		}
+		targets, err = findTargets(api.NewClient(token), filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		if format == output.Text {
+			fmt.Println("No mappings to delete")
+			return nil
+		}
+		return output.Print(map[string]interface{}{
+			"status":  "success",
+			"results": []bulkResult{},
+		}, output.Options{Format: format, Kind: output.KindMappingDeletionList})
+	}
+
+	if !yes && !dryRun {
+		fmt.Fprintf(os.Stderr, "%d mapping(s) will be deleted:\n", len(targets))
+		for _, t := range targets {
+			fmt.Fprintf(os.Stderr, "  %s  %s\n", t.id, t.description)
+		}
+		confirmed, err := input.Confirm(fmt.Sprintf("Delete %d mapping(s)?", len(targets)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("aborted")
+		}
+	}
+
+	results := deleteTargets(token, targets, parallel, dryRun)
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+
+	if format == output.Text {
+		w := tabwriter.NewWriter(output.GetWriter(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tMAPPING\tRESULT")
+		fmt.Fprintln(w, "--\t-------\t------")
+		for _, r := range results {
+			result := r.Status
+			if r.Error != "" {
+				result += ": " + r.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", r.ID, r.Mapping, result)
+		}
+		w.Flush()
+	} else {
+		status := "success"
+		if failed > 0 {
+			status = "error"
+		}
+		if err := output.Print(map[string]interface{}{
+			"status":  status,
+			"results": results,
+		}, output.Options{Format: format, Kind: output.KindMappingDeletionList}); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d mapping(s) could not be deleted", failed, len(results))
+	}
+	return nil
+}
+
+// readTargets reads whitespace separated mapping IDs, as printed by jq or a list column
+func readTargets(r io.Reader) ([]bulkTarget, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+
+	var targets []bulkTarget
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		id := strings.Trim(scanner.Text(), `"',`)
+		if id == "" || seen[id] {
+			continue
+		}
+		if valid, msg := validation.IsValidID(id); !valid {
+			return nil, fmt.Errorf("invalid mapping ID %q on stdin: %s", id, msg)
+		}
+		seen[id] = true
+		targets = append(targets, bulkTarget{id: id})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mapping IDs: %w", err)
+	}
+	return targets, nil
+}
+
+// findTargets lists the mappings matching filter
+func findTargets(client api.Client, filter bulkFilter) ([]bulkTarget, error) {
+	params := map[string]string{
+		"config_id": filter.configID,
+		"protocol":  filter.protocol,
+	}
+	mappings, err := client.ListMappings(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mappings: %w", err)
+	}
+
+	var targets []bulkTarget
+	if response, ok := mappings.(map[string]interface{}); ok {
+		if data, ok := response["data"].([]interface{}); ok {
+			for _, item := range data {
+				mapping, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				// Filter locally as well, the hostname pattern has no API equivalent
+				configID, region := mappingConfig(mapping)
+				if filter.configID != "" && configID != filter.configID {
+					continue
+				}
+				if filter.protocol != "" && fmt.Sprintf("%v", mapping["protocol"]) != filter.protocol {
+					continue
+				}
+				if filter.hostname != "" {
+					if matched, _ := path.Match(filter.hostname, fmt.Sprintf("%v", mapping["hostname"])); !matched {
+						continue
+					}
+				}
+
+				targets = append(targets, bulkTarget{
+					id:          api.FormatID(mapping["id"]),
+					description: describeMapping(mapping),
+					region:      region,
+				})
+			}
+		}
+	}
+	return targets, nil
+}
+
+// deleteTargets deletes up to parallel mappings at a time and returns a result per target, in order
+func deleteTargets(token string, targets []bulkTarget, parallel int, dryRun bool) []bulkResult {
+	results := make([]bulkResult, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t bulkTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = deleteTarget(token, t, dryRun)
+		}(i, t)
+	}
+	wg.Wait()
+	return results
+}
+
+func deleteTarget(token string, t bulkTarget, dryRun bool) bulkResult {
+	result := bulkResult{ID: t.id, Mapping: t.description}
+	client := api.NewClient(token)
+
+	// Mappings given by ID have to be looked up to find their region
+	if t.description == "" {
+		mapping, err := client.GetMapping(t.id)
+		if err != nil {
+			result.Status, result.Error = "failed", err.Error()
+			return result
+		}
+		if response, ok := mapping.(map[string]interface{}); ok {
+			if data, ok := response["data"].(map[string]interface{}); ok {
+				result.Mapping = describeMapping(data)
+				_, t.region = mappingConfig(data)
+			}
+		}
+	}
+	if t.region != "" && t.region != "default" {
+		client = api.NewClientWithBaseURL(token, regions.APIURL(t.region))
+	}
+
+	if dryRun {
+		result.Status = "dry run: DELETE /mappings/" + t.id
+		return result
+	}
+	if err := client.DeleteMapping(t.id); err != nil {
+		result.Status, result.Error = "failed", err.Error()
+		return result
+	}
+	result.Status = "deleted"
+	return result
+}
+
+// mappingConfig returns the ID and region of the config a mapping belongs to
+func mappingConfig(mapping map[string]interface{}) (string, string) {
+	configID := api.FormatID(mapping["config_id"])
+	region, _ := mapping["region"].(string)
+	if config, ok := mapping["config"].(map[string]interface{}); ok {
+		configID = api.FormatID(config["id"])
+		if r, ok := config["region"].(string); ok && r != "" {
+			region = r
+		}
+	}
+	return configID, region
+}
+
+func describeMapping(mapping map[string]interface{}) string {
+	return fmt.Sprintf("%v://%v:%v -> %v", mapping["protocol"], mapping["hostname"], mapping["port_from"], mapping["port_to"])
+}
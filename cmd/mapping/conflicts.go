@@ -0,0 +1,185 @@
+package mapping
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/validation"
+)
+
+// portRule is the part of a mapping that can clash with another one
+type portRule struct {
+	id          string
+	configID    string
+	hostname    string
+	protocol    string
+	portFrom    int
+	portTo      int
+	description string
+}
+
+// listRules lists the mappings of the account, as they are now: one created
+// a moment ago must not be missed. Hostnames are lower-cased for conflicts.
+func listRules(client api.Client) ([]portRule, error) {
+	api.SetCacheTTL(0)
+	mappings, err := client.ListMappings(map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mappings: %w", err)
+	}
+
+	var rules []portRule
+	if response, ok := mappings.(map[string]interface{}); ok {
+		if data, ok := response["data"].([]interface{}); ok {
+			for _, item := range data {
+				mapping, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				configID, _ := mappingConfig(mapping)
+				portFrom, _ := strconv.Atoi(api.FormatID(mapping["port_from"]))
+				portTo, _ := strconv.Atoi(api.FormatID(mapping["port_to"]))
+				rules = append(rules, portRule{
+					id:          api.FormatID(mapping["id"]),
+					configID:    normalizeID(configID),
+					hostname:    strings.ToLower(fmt.Sprintf("%v", mapping["hostname"])),
+					protocol:    fmt.Sprintf("%v", mapping["protocol"]),
+					portFrom:    portFrom,
+					portTo:      portTo,
+					description: describeMapping(mapping),
+				})
+			}
+		}
+	}
+	return rules, nil
+}
+
+// normalizeID formats a numeric ID the way api.FormatID does, so IDs that went
+// through %v, such as 1.2345678e+07, still match
+func normalizeID(id string) string {
+	if n, err := strconv.ParseFloat(id, 64); err == nil {
+		return api.FormatID(n)
+	}
+	return id
+}
+
+// transport is what a protocol runs over. Only rules sharing it clash: a udp
+// mapping may use the port of a tcp one.
+func transport(protocol string) string {
+	if protocol == "udp" {
+		return "udp"
+	}
+	return "tcp"
+}
+
+// conflicts checks a new rule against those of the account: its hostname and
+// port_from must not be taken, nor its port_to on the same config. Hostnames
+// are compared in lower case, as DNS does. Each problem suggests a free port
+// or hostname to use instead.
+func conflicts(r portRule, configType string, rules []portRule) validation.Errors {
+	var problems validation.Errors
+	r.configID = normalizeID(r.configID)
+	r.hostname = strings.ToLower(r.hostname)
+	var sameFrom, sameTo *portRule
+	for i, other := range rules {
+		if transport(other.protocol) != transport(r.protocol) {
+			continue
+		}
+		if sameFrom == nil && other.hostname == r.hostname && other.portFrom == r.portFrom {
+			sameFrom = &rules[i]
+		}
+		if sameTo == nil && other.configID == r.configID && other.portTo == r.portTo {
+			sameTo = &rules[i]
+		}
+	}
+
+	if sameFrom != nil {
+		msg := fmt.Sprintf("%s:%d is already used by mapping %s (%s)", r.hostname, r.portFrom, sameFrom.id, sameFrom.description)
+		// 80 and 443 have no equivalent, another hostname keeps the port
+		if r.portFrom == 80 || r.portFrom == 443 {
+			msg += ", try --hostname " + freeHostname(r, rules)
+		} else if port := freePortFrom(r, configType, rules); port != 0 {
+			msg += fmt.Sprintf(", try --port-from %d", port)
+		}
+		problems.Add("--port-from", msg)
+	}
+	if sameTo != nil {
+		msg := fmt.Sprintf("port %d of config %s is already forwarded to by mapping %s (%s)", r.portTo, r.configID, sameTo.id, sameTo.description)
+		if port := freePortTo(r, rules); port != 0 {
+			msg += fmt.Sprintf(", try --port-to %d", port)
+		}
+		problems.Add("--port-to", msg)
+	}
+	return problems
+}
+
+// freePortFrom returns the first port after that of r which is free on its
+// hostname, or 0 when there is none
+func freePortFrom(r portRule, configType string, rules []portRule) int {
+	used := make(map[int]bool)
+	for _, other := range rules {
+		if other.hostname == r.hostname && transport(other.protocol) == transport(r.protocol) {
+			used[other.portFrom] = true
+		}
+	}
+	return nextFree(r.portFrom, 1024, used, func(port int) bool {
+		valid, _ := validation.IsValidPort(strconv.Itoa(port), r.protocol, configType)
+		return valid
+	})
+}
+
+// freePortTo returns the first port after that of r which is not forwarded
+// to on its config, or 0 when there is none
+func freePortTo(r portRule, rules []portRule) int {
+	used := make(map[int]bool)
+	for _, other := range rules {
+		if other.configID == r.configID && transport(other.protocol) == transport(r.protocol) {
+			used[other.portTo] = true
+		}
+	}
+	return nextFree(r.portTo, 1, used, func(int) bool { return true })
+}
+
+// nextFree searches the ports after port, then those from lowest on, for one
+// that is not used and allowed
+func nextFree(port, lowest int, used map[int]bool, allowed func(int) bool) int {
+	for i := 1; i <= 65535; i++ {
+		candidate := port + i
+		if candidate > 65535 {
+			candidate = lowest + candidate - 65536
+		}
+		if candidate >= lowest && candidate != port && !used[candidate] && allowed(candidate) {
+			return candidate
+		}
+	}
+	return 0
+}
+
+// freeHostname returns a hostname of the account on which the port of r is
+// free, or else one derived from the hostname of r
+func freeHostname(r portRule, rules []portRule) string {
+	taken := make(map[string]bool)
+	var hostnames []string
+	for _, other := range rules {
+		if other.portFrom == r.portFrom && transport(other.protocol) == transport(r.protocol) {
+			taken[other.hostname] = true
+		}
+		hostnames = append(hostnames, other.hostname)
+	}
+	sort.Strings(hostnames)
+	for _, hostname := range hostnames {
+		if !taken[hostname] {
+			return hostname
+		}
+	}
+
+	name, domain, _ := strings.Cut(r.hostname, ".")
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d.%s", name, i, domain)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
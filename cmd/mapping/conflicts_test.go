@@ -0,0 +1,50 @@
+package mapping
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"portmap.io/client/internal/validation"
+)
+
+func TestConflicts(t *testing.T) {
+	rules := []portRule{
+		{id: "7", configID: "12", hostname: "app.portmap.io", protocol: "https", portFrom: 443, portTo: 8080, description: "https://app.portmap.io:443 -> 8080"},
+		{id: "8", configID: "12", hostname: "app.portmap.io", protocol: "tcp", portFrom: 2000, portTo: 22, description: "tcp://app.portmap.io:2000 -> 22"},
+		{id: "9", configID: "12", hostname: "app.portmap.io", protocol: "tcp", portFrom: 2001, portTo: 23, description: "tcp://app.portmap.io:2001 -> 23"},
+		{id: "10", configID: "13", hostname: "db.portmap.io", protocol: "tcp", portFrom: 5432, portTo: 5432, description: "tcp://db.portmap.io:5432 -> 5432"},
+	}
+
+	// Free hostname, port and target
+	assert.Empty(t, conflicts(portRule{configID: "12", hostname: "app.portmap.io", protocol: "tcp", portFrom: 3000, portTo: 3000}, "WireGuard", rules))
+
+	// udp doesn't clash with tcp
+	assert.Empty(t, conflicts(portRule{configID: "12", hostname: "app.portmap.io", protocol: "udp", portFrom: 2000, portTo: 22}, "WireGuard", rules))
+
+	// The target port is only taken on the same config
+	assert.Empty(t, conflicts(portRule{configID: "12", hostname: "app.portmap.io", protocol: "tcp", portFrom: 3000, portTo: 5432}, "WireGuard", rules))
+
+	assert.Equal(t, validation.Errors{
+		{Field: "--port-from", Message: "app.portmap.io:2000 is already used by mapping 8 (tcp://app.portmap.io:2000 -> 22), try --port-from 2002"},
+		{Field: "--port-to", Message: "port 22 of config 12 is already forwarded to by mapping 8 (tcp://app.portmap.io:2000 -> 22), try --port-to 24"},
+	}, conflicts(portRule{configID: "12", hostname: "app.portmap.io", protocol: "tcp", portFrom: 2000, portTo: 22}, "WireGuard", rules))
+
+	// Hostnames match in any case
+	assert.Len(t, conflicts(portRule{configID: "13", hostname: "App.Portmap.io", protocol: "tcp", portFrom: 2000, portTo: 2000}, "WireGuard", rules), 1)
+
+	// Config IDs match however they were formatted
+	assert.Len(t, conflicts(portRule{configID: "1.3e+01", hostname: "db.portmap.io", protocol: "tcp", portFrom: 6000, portTo: 5432}, "WireGuard", rules), 1)
+
+	// 443 keeps its port on another hostname, one of the account if free
+	assert.Equal(t, validation.Errors{
+		{Field: "--port-from", Message: "app.portmap.io:443 is already used by mapping 7 (https://app.portmap.io:443 -> 8080), try --hostname db.portmap.io"},
+	}, conflicts(portRule{configID: "12", hostname: "app.portmap.io", protocol: "https", portFrom: 443, portTo: 9000}, "WireGuard", rules))
+	assert.Equal(t, "app-2.portmap.io", freeHostname(portRule{hostname: "app.portmap.io", protocol: "https", portFrom: 443}, rules[:1]))
+}
+
+func TestNextFree(t *testing.T) {
+	used := map[int]bool{65535: true, 1024: true}
+	assert.Equal(t, 1025, nextFree(65534, 1024, used, func(int) bool { return true }))
+	assert.Equal(t, 1195, nextFree(1193, 1024, nil, func(port int) bool { return port != 1194 }))
+	assert.Equal(t, 0, nextFree(80, 80, nil, func(int) bool { return false }))
+}
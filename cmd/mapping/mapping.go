@@ -1,16 +1,17 @@
 package mapping
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 	"portmap.io/client/internal/api"
-	"portmap.io/client/internal/common"
+	"portmap.io/client/internal/completion"
 	"portmap.io/client/internal/input"
 	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
 	"portmap.io/client/internal/validation"
 	"portmap.io/client/pkg/config" // Update import path
 )
@@ -34,6 +35,9 @@ func NewCommand() *cobra.Command {
 func newListCommand() *cobra.Command {
 	var region, mappingType, protocol, configID string
 	var columns []string
+	var sortBy, filter string
+	var noHeaders, watch bool
+	var interval time.Duration
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -42,10 +46,20 @@ func newListCommand() *cobra.Command {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
 
+			if len(columns) == 1 && columns[0] == "help" {
+				output.PrintColumns(os.Stdout, output.MappingColumns)
+				return nil
+			}
+
 			format, err := output.ParseFormat(outputFormat)
 			if err != nil {
 				return err
 			}
+			opts, err := output.ListOptions(format, output.MappingColumns, columns, sortBy, filter, noHeaders)
+			if err != nil {
+				return err
+			}
+			opts.Kind = output.KindMappingList
 
 			// Load config to get default region
 			cfg, err := config.LoadConfig(cmd.Flag("env-file").Value.String())
@@ -73,12 +87,24 @@ func newListCommand() *cobra.Command {
 				params["config_id"] = configID
 			}
 
-			opts := output.Options{
-				Format:  format,
-				Columns: columns,
+			client := api.NewClient(token)
+			if watch {
+				api.SetCacheTTL(0)
+				return output.Watch(cmd.CommandPath(), interval, func() (interface{}, error) {
+					return client.ListMappings(params)
+				}, opts)
+			}
+
+			// Rows are written as the list arrives unless filtering or sorting needs all of it
+			if streamer, ok := client.(api.ListStreamer); ok && output.Streams(opts) {
+				body, err := streamer.StreamMappings(params)
+				if err != nil {
+					return err
+				}
+				defer body.Close()
+				return output.PrintStream(body, opts)
 			}
 
-			client := api.NewClient(token)
 			mappings, err := client.ListMappings(params)
 			if err != nil {
 				return err
@@ -88,21 +114,36 @@ func newListCommand() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&region, "region", "", "Filter by region (default, nyc1, fra1, blr1, sin1)")
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Filter by region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
 	cmd.Flags().StringVar(&mappingType, "type", "", "Filter by type (OpenVPN, SSH, WireGuard)")
 	cmd.Flags().StringVar(&protocol, "protocol", "", "Filter by protocol (tcp, udp, http, https)")
 	cmd.Flags().StringVar(&configID, "config-id", "", "Filter by configuration ID")
+	cmd.RegisterFlagCompletionFunc("type", completion.ConfigTypes)
+	cmd.RegisterFlagCompletionFunc("protocol", completion.Protocols)
+	cmd.RegisterFlagCompletionFunc("config-id", completion.ConfigIDs)
 	// Add columns flag
-	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to display (comma-separated)")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Columns to display (comma-separated), or help to list them")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "Column to sort by, prefixed with - for descending order")
+	cmd.Flags().StringVar(&filter, "filter", "", "Only show rows matching all conditions, e.g. 'port_from>=8000,hostname=*.dev.portmap.io'")
+	cmd.Flags().BoolVar(&noHeaders, "no-headers", false, "Don't print the header line of text, CSV and TSV output")
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Poll and redraw the list, highlighting changes (JSON and NDJSON print change events)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls with --watch")
+	cmd.RegisterFlagCompletionFunc("columns", completion.Columns(output.MappingColumns))
+	cmd.RegisterFlagCompletionFunc("sort-by", completion.Columns(output.MappingColumns))
 
 	return cmd
 }
 
 func newShowCommand() *cobra.Command {
+	var watch bool
+	var interval time.Duration
+
 	cmd := &cobra.Command{
-		Use:   "show [mapping-id]",
-		Short: "Show mapping rule details",
-		Args:  cobra.ExactArgs(1),
+		Use:               "show [mapping-id]",
+		ValidArgsFunction: completion.MappingIDs,
+		Short:             "Show mapping rule details",
+		Args:              cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
@@ -118,36 +159,59 @@ func newShowCommand() *cobra.Command {
 			}
 
 			client := api.NewClient(token)
+			opts := output.Options{
+				Format: format,
+				Kind:   output.KindMapping,
+			}
+			if watch {
+				api.SetCacheTTL(0)
+				return output.Watch(cmd.CommandPath()+" "+args[0], interval, func() (interface{}, error) {
+					return client.GetMapping(args[0])
+				}, opts)
+			}
+
 			mapping, err := client.GetMapping(args[0])
 			if err != nil {
 				return err
 			}
 
-			opts := output.Options{
-				Format: format,
-			}
 			return output.Print(mapping, opts)
 		},
 	}
 
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Poll and redraw the mapping, highlighting changes (JSON and NDJSON print change events)")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Time between polls with --watch")
+
 	return cmd
 }
 
+// mappingFlags are the flags setting the fields of a validation.MappingSpec
+var mappingFlags = map[string]string{
+	"hostname":   "--hostname",
+	"protocol":   "--protocol",
+	"port_from":  "--port-from",
+	"port_to":    "--port-to",
+	"hostheader": "--hostheader",
+	"allowed_ip": "--allowed-ip",
+	"websockets": "--websockets",
+	"ws_timeout": "--ws-timeout",
+}
+
 func newCreateCommand() *cobra.Command {
 	// Add region to existing variables
 	var hostname, protocol, portFrom, portTo, configID, hostheader, allowedIP, region string
 	var useCustomDomain, websockets bool
 	var wsTimeout int
 	var configType string
-	var proxyToHTTP bool
+	var proxyToHTTP, force bool
 
 	cmd := &cobra.Command{
-		Use:   "create",
-		Short: "Create a new mapping rule",
+		Use:          "create",
+		Short:        "Create a new mapping rule",
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
-			reader := bufio.NewReader(os.Stdin)
 
 			// Load config to get default region
 			cfg, err := config.LoadConfig(cmd.Flag("env-file").Value.String())
@@ -166,280 +230,206 @@ func newCreateCommand() *cobra.Command {
 				configParams["region"] = region
 			}
 
-			// First, get or validate config ID and get config type
-			if configID == "" {
-				fmt.Printf("\nAvailable configurations in region %s:\n", cfg.Region)
-				client := api.NewClient(token)
-				configs, err := client.ListConfigs(configParams)
-				if err != nil {
-					return fmt.Errorf("failed to list configurations: %w", err)
-				}
-
-				// Display available configurations
-				if configList, ok := configs.(map[string]interface{}); ok {
-					if data, ok := configList["data"].([]interface{}); ok {
-						if len(data) == 0 {
-							return fmt.Errorf("no configurations found in region %s", cfg.Region)
-						}
-						for _, conf := range data {
-							if config, ok := conf.(map[string]interface{}); ok {
-								fmt.Printf("ID: %v, Name: %v, Type: %v, Region: %v\n",
-									config["id"],
-									config["name"],
-									config["type"],
-									config["region"])
-							}
-						}
-					}
+			// The configs checked against have to be current
+			api.SetCacheTTL(0)
+			prompter := input.NewPrompter()
+			client := api.NewClient(token)
+			configs, err := client.ListConfigs(configParams)
+			if err != nil {
+				return fmt.Errorf("failed to list configurations: %w", err)
+			}
+			configList, _ := configs.(map[string]interface{})
+			data, _ := configList["data"].([]interface{})
+
+			// The rule as given so far, with its fields named by their flags
+			spec := func() validation.MappingSpec {
+				return validation.MappingSpec{
+					ConfigType: configType,
+					Hostname:   hostname,
+					Protocol:   protocol,
+					PortFrom:   portFrom,
+					PortTo:     portTo,
+					HostHeader: hostheader,
+					AllowedIP:  allowedIP,
+					WebSockets: websockets,
+					WSTimeout:  wsTimeout,
 				}
+			}
 
-				// Get and validate config ID
-				for {
-					configID, err = input.PromptForValue(reader, "Config ID", true)
-					if err != nil {
-						return err
-					}
-
-					if valid, msg := validation.IsValidID(configID); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-
-					if !validation.IsValidConfigID(configID, configs.(map[string]interface{})) {
-						fmt.Println("Error: Invalid config ID. Please select from the list above")
-						continue
-					}
+			// Every flag is checked before anything is asked, so all mistakes are
+			// reported at once. Without input, missing flags are mistakes too.
+			var problems validation.Errors
+			if configID == "" {
+				problems.Add("--config-id", validation.Required)
+			} else if valid, msg := validation.IsValidID(configID); !valid {
+				problems.Add("--config-id", msg)
+			} else if !validation.IsValidConfigID(configID, configList) {
+				problems.Add("--config-id", fmt.Sprintf("%s is not in the list of available configurations", configID))
+			}
+			configType = configTypeOf(data, configID)
+			problems = append(problems, spec().Validate().Rename(mappingFlags)...)
+			if prompter.Interactive() {
+				problems = problems.Invalid()
+			}
+			if err := problems.Err(); err != nil {
+				return err
+			}
 
-					// Extract config type here
-					if configList, ok := configs.(map[string]interface{}); ok {
-						if data, ok := configList["data"].([]interface{}); ok {
-							for _, conf := range data {
-								if config, ok := conf.(map[string]interface{}); ok {
-									if fmt.Sprintf("%v", config["id"]) == configID {
-										configType = fmt.Sprintf("%v", config["type"])
-										break
-									}
-								}
-							}
-						}
-					}
-					break
+			// Then ask for what is missing
+			if configID == "" {
+				if len(data) == 0 {
+					return fmt.Errorf("no configurations found in region %s", cfg.Region)
 				}
-			} else {
-				// Validate config ID provided via flag
-				if valid, msg := validation.IsValidID(configID); !valid {
-					return fmt.Errorf("invalid config ID: %s", msg)
+				var options []input.Option
+				for _, conf := range data {
+					if config, ok := conf.(map[string]interface{}); ok {
+						options = append(options, input.Option{
+							Label: fmt.Sprintf("%s  %v", api.FormatID(config["id"]), config["name"]),
+							Value: api.FormatID(config["id"]),
+							Hint:  fmt.Sprintf("%v, %v", config["type"], config["region"]),
+						})
+					}
 				}
-
-				client := api.NewClient(token)
-				configs, err := client.ListConfigs(configParams)
+				configID, err = prompter.Select("Configuration", options, "")
 				if err != nil {
-					return fmt.Errorf("failed to list configurations: %w", err)
-				}
-
-				if !validation.IsValidConfigID(configID, configs.(map[string]interface{})) {
-					return fmt.Errorf("invalid config ID: %s is not in the list of available configurations", configID)
-				}
-
-				// Extract config type here
-				if configList, ok := configs.(map[string]interface{}); ok {
-					if data, ok := configList["data"].([]interface{}); ok {
-						for _, conf := range data {
-							if config, ok := conf.(map[string]interface{}); ok {
-								if fmt.Sprintf("%v", config["id"]) == configID {
-									configType = fmt.Sprintf("%v", config["type"])
-									break
-								}
-							}
-						}
-					}
+					return err
 				}
+				configType = configTypeOf(data, configID)
 			}
 
-			// Then prompt for hostname and protocol
-			// Check and prompt for required parameters if missing
-			// Update the hostname validation block
 			if hostname == "" {
-				for {
-					hostname, err = input.PromptForValue(reader, "Hostname (must end with .portmap.io or .portmap.host)", true)
-					if err != nil {
-						return err
-					}
-
-					if valid, msg := validation.IsValidHostname(hostname); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
+				hostname, err = prompter.Text("Hostname (must end with .portmap.io or .portmap.host)", "", validation.IsValidHostname)
+				if err != nil {
+					return err
 				}
-			} else if valid, msg := validation.IsValidHostname(hostname); !valid {
-				return fmt.Errorf("invalid hostname: %s", msg)
 			}
 
-			// Update the protocol selection block
 			if protocol == "" {
-				for {
-					fmt.Println("\nSelect protocol:")
-					fmt.Println("1. tcp")
-					fmt.Println("2. udp")
-					fmt.Println("3. http")
-					fmt.Println("4. https")
-					protocolNum, err := input.PromptForValue(reader, "Protocol number (1-4)", true)
-					if err != nil {
-						return err
-					}
-					protocolMap := map[string]string{
-						"1": "tcp",
-						"2": "udp",
-						"3": "http",
-						"4": "https",
-					}
-					if p, ok := protocolMap[protocolNum]; ok {
-						protocol = p
-						if valid, msg := validation.IsValidProtocol(protocol); !valid {
-							fmt.Printf("Error: %s\n", msg)
-							continue
-						}
-						break
-					}
-					fmt.Println("Error: Invalid protocol selection")
+				protocol, err = prompter.Select("Protocol", input.Options("tcp", "udp", "http", "https"), "")
+				if err != nil {
+					return err
 				}
-			} else if valid, msg := validation.IsValidProtocol(protocol); !valid {
-				return fmt.Errorf("invalid protocol: %s", msg)
 			}
 
 			// Now validate ports with known config type
+			validPort := func(port string) (bool, string) {
+				return validation.IsValidPort(port, protocol, configType)
+			}
 			if portFrom == "" {
-				for {
-					portFrom, err = input.PromptForValue(reader, "Port From (1024-65535, 80/443 for special cases)", true)
-					if err != nil {
-						return err
-					}
-
-					if valid, msg := validation.IsValidPort(portFrom, protocol, configType); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
-				}
-			} else {
-				if valid, msg := validation.IsValidPort(portFrom, protocol, configType); !valid {
-					return fmt.Errorf("invalid port: %s", msg)
+				portFrom, err = prompter.Text("Port from (1024-65535, 80/443 for special cases)", "", validPort)
+				if err != nil {
+					return err
 				}
 			}
 
 			if portTo == "" {
-				for {
-					var err error
-					portTo, err = input.PromptForValue(reader, "Port To (1-65535)", true)
-					if err != nil {
-						return err
-					}
-
-					if valid, msg := validation.IsValidPortNumber(portTo); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
-				}
-			} else {
-				// Validate port-to provided via flag
-				if valid, msg := validation.IsValidPortNumber(portTo); !valid {
-					return fmt.Errorf("invalid port-to: %s", msg)
+				portTo, err = prompter.Text("Port to (1-65535)", "", validation.IsValidPortNumber)
+				if err != nil {
+					return err
 				}
 			}
 
-			// Add HTTPS proxy option for HTTPS protocol
-			if protocol == "https" {
-				if !cmd.Flags().Changed("proxy-to-http") {
-					proxyStr, _ := input.PromptForValue(reader, "Proxy HTTPS to HTTP? (Y/n)", false)
-					// Default to true if empty or 'y'
-					proxyToHTTP = proxyStr == "" || strings.ToLower(proxyStr) == "y"
+			// Optional settings are only asked for, without input they keep their defaults
+			if prompter.Interactive() {
+				// Add HTTPS proxy option for HTTPS protocol
+				if protocol == "https" && !cmd.Flags().Changed("proxy-to-http") {
+					proxyToHTTP, err = prompter.Confirm("Proxy HTTPS to HTTP?", true)
+					if err != nil {
+						return err
+					}
 				}
-			}
 
-			// Optional parameters if not provided
-			// Skip web-specific options for TCP/UDP protocols
-			if protocol != "tcp" && protocol != "udp" {
-				// Host header prompt (only for HTTP/HTTPS)
-				if hostheader == "" {
-					for {
-						hostheader, _ = input.PromptForValue(reader, "Host Header (optional)", false)
-						if hostheader == "" {
-							break
-						}
-						if valid, msg := validation.IsValidHostHeader(hostheader); !valid {
-							fmt.Printf("Error: %s\n", msg)
-							continue
+				// Skip web-specific options for TCP/UDP protocols
+				if protocol != "tcp" && protocol != "udp" {
+					if hostheader == "" {
+						hostheader, err = prompter.Text("Host header (optional)", "", validation.IsValidHostHeader)
+						if err != nil {
+							return err
 						}
-						break
 					}
-				} else if valid, msg := validation.IsValidHostHeader(hostheader); !valid {
-					return fmt.Errorf("invalid host header: %s", msg)
-				}
 
-				// Custom domain prompt (only for HTTP/HTTPS)
-				if !cmd.Flags().Changed("use-custom-domain") {
-					customDomain, _ := input.PromptForValue(reader, "Use Custom Domain? (y/n)", false)
-					useCustomDomain = strings.ToLower(customDomain) == "y"
-				}
+					if !cmd.Flags().Changed("use-custom-domain") {
+						useCustomDomain, err = prompter.Confirm("Use custom domain?", false)
+						if err != nil {
+							return err
+						}
+					}
 
-				// WebSocket prompts (only for HTTP/HTTPS)
-				if !cmd.Flags().Changed("websockets") {
-					websocketsStr, _ := input.PromptForValue(reader, "Enable WebSockets? (y/n)", false)
-					websockets = strings.ToLower(websocketsStr) == "y"
+					if !cmd.Flags().Changed("websockets") {
+						websockets, err = prompter.Confirm("Enable WebSockets?", false)
+						if err != nil {
+							return err
+						}
+					}
 					if websockets && !cmd.Flags().Changed("ws-timeout") {
-						wsTimeoutStr, _ := input.PromptForValue(reader, "WebSocket Timeout (seconds)", false)
-						if wsTimeoutStr != "" {
-							fmt.Sscanf(wsTimeoutStr, "%d", &wsTimeout)
+						timeout, err := prompter.Text("WebSocket timeout in seconds", strconv.Itoa(wsTimeout), validWSTimeout)
+						if err != nil {
+							return err
 						}
+						wsTimeout, _ = strconv.Atoi(timeout)
 					}
 				}
-			}
 
-			// Allowed IP prompt (for all protocols)
-			if allowedIP == "" {
-				for {
-					allowedIP, _ = input.PromptForValue(reader, "Allowed IP CIDR (optional)", false)
-					if allowedIP == "" {
-						break
-					}
-					if valid, msg := validation.IsValidCIDR(allowedIP); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
+				// Allowed IP prompt (for all protocols)
+				if allowedIP == "" {
+					allowedIP, err = prompter.Text("Allowed IP CIDR (optional)", "", validation.IsValidCIDR)
+					if err != nil {
+						return err
 					}
-					break
 				}
-			} else if valid, msg := validation.IsValidCIDR(allowedIP); !valid {
-				return fmt.Errorf("invalid CIDR: %s", msg)
 			}
 
-			// Update WebSocket timeout validation
-			// Update the WebSocket timeout validation block
-			if websockets {
-				for {
-					if cmd.Flags().Changed("ws-timeout") {
-						if valid, msg := validation.IsValidWSTimeout(wsTimeout); !valid {
-							return fmt.Errorf("invalid WebSocket timeout: %s", msg)
-						}
-						break
-					}
+			// Flags and answers together must still make a valid rule, such as
+			// a port given as a flag with the protocol picked afterwards
+			if err := spec().Validate().Rename(mappingFlags).Err(); err != nil {
+				return err
+			}
 
-					wsTimeoutStr, _ := input.PromptForValue(reader, "WebSocket Timeout (seconds)", false)
-					if wsTimeoutStr == "" {
-						break
-					}
+			// Rules already on the account would only be refused with an opaque
+			// API error, so they are looked for first
+			if !force {
+				rules, err := listRules(client)
+				if err != nil {
+					return fmt.Errorf("failed to check for conflicting mappings: %w", err)
+				}
+				from, _ := strconv.Atoi(portFrom)
+				to, _ := strconv.Atoi(portTo)
+				rule := portRule{configID: configID, hostname: hostname, protocol: protocol, portFrom: from, portTo: to}
+				if err := conflicts(rule, configType, rules).Err(); err != nil {
+					return fmt.Errorf("%w\nuse --force to create the mapping anyway", err)
+				}
+			}
 
-					if _, err := fmt.Sscanf(wsTimeoutStr, "%d", &wsTimeout); err != nil {
-						fmt.Println("Error: Timeout must be a number")
-						continue
-					}
+			// Show what was put together before submitting it
+			if prompter.Asked() {
+				fields := []input.Field{
+					{Name: "Configuration", Value: fmt.Sprintf("%s (%s, %s)", configID, configNameOf(data, configID), configType)},
+					{Name: "Hostname", Value: hostname},
+					{Name: "Protocol", Value: protocol},
+					{Name: "Port from", Value: portFrom},
+					{Name: "Port to", Value: portTo},
+				}
+				if protocol == "https" {
+					fields = append(fields, input.Field{Name: "Proxy to HTTP", Value: yesNo(proxyToHTTP)})
+				}
+				if protocol != "tcp" && protocol != "udp" {
+					fields = append(fields,
+						input.Field{Name: "Host header", Value: hostheader},
+						input.Field{Name: "Custom domain", Value: yesNo(useCustomDomain)},
+						input.Field{Name: "WebSockets", Value: yesNo(websockets)},
+					)
+				}
+				if websockets && wsTimeout > 0 {
+					fields = append(fields, input.Field{Name: "WebSocket timeout", Value: fmt.Sprintf("%ds", wsTimeout)})
+				}
+				fields = append(fields, input.Field{Name: "Allowed IP", Value: allowedIP})
 
-					if valid, msg := validation.IsValidWSTimeout(wsTimeout); !valid {
-						fmt.Printf("Error: %s\n", msg)
-						continue
-					}
-					break
+				confirmed, err := prompter.Review("New mapping", fields)
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return fmt.Errorf("mapping not created")
 				}
 			}
 
@@ -450,8 +440,8 @@ func newCreateCommand() *cobra.Command {
 			}
 
 			// Create client with region-specific domain
-			baseURL := fmt.Sprintf("https://%s/api", common.GetDomainWithRegion(region))
-			client := api.NewClientWithBaseURL(token, baseURL)
+			baseURL := regions.APIURL(region)
+			client = api.NewClientWithBaseURL(token, baseURL)
 
 			mapping := api.MappingRequest{
 				Hostname:        hostname,
@@ -467,6 +457,10 @@ func newCreateCommand() *cobra.Command {
 				ProxyToHTTP:     proxyToHTTP,
 			}
 
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return output.PrintDryRun("POST", "/mappings", mapping, format)
+			}
+
 			result, err := client.CreateMapping(mapping)
 			if err != nil {
 				return err
@@ -474,6 +468,7 @@ func newCreateCommand() *cobra.Command {
 
 			opts := output.Options{
 				Format: format,
+				Kind:   output.KindMapping,
 			}
 			return output.Print(result, opts)
 		},
@@ -487,60 +482,112 @@ func newCreateCommand() *cobra.Command {
 	cmd.Flags().StringVar(&configID, "config-id", "", "Configuration ID")
 	cmd.Flags().StringVar(&hostheader, "hostheader", "", "Host header")
 	cmd.Flags().StringVar(&allowedIP, "allowed-ip", "", "Allowed IP CIDR")
-	cmd.Flags().StringVar(&region, "region", "", "Region (default, nyc1, fra1, blr1, sin1)")
+	cmd.RegisterFlagCompletionFunc("protocol", completion.Protocols)
+	cmd.RegisterFlagCompletionFunc("config-id", completion.ConfigIDs)
+	cmd.Flags().StringVar(&region, "region", "", regions.FlagUsage("Region"))
+	cmd.RegisterFlagCompletionFunc("region", completion.Regions)
 	cmd.Flags().BoolVar(&useCustomDomain, "use-custom-domain", false, "Use custom domain")
 	cmd.Flags().BoolVar(&websockets, "websockets", false, "Enable WebSocket support")
 	cmd.Flags().IntVar(&wsTimeout, "ws-timeout", 30, "WebSocket timeout in seconds")
 	cmd.Flags().BoolVar(&proxyToHTTP, "proxy-to-http", true, "Proxy HTTPS to HTTP backend (HTTPS only, default: true)")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip the check for mappings using the same hostname and port, or the same target port")
 
 	return cmd
 }
 
 func newDeleteCommand() *cobra.Command {
+	var yes bool
+	var filter bulkFilter
+	var parallel int
+
 	cmd := &cobra.Command{
-		Use:   "delete [mapping-id]",
-		Short: "Delete a mapping rule",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete [mapping-id | -]",
+		ValidArgsFunction: completion.MappingIDs,
+		Short:             "Delete mapping rules",
+		Long: "Delete a mapping rule, the mapping rules whose IDs are read from stdin (-), or every\n" +
+			"mapping rule matching the filters. The mappings are shown and confirmation is asked\n" +
+			"for first, unless --yes is given.",
+		Example: "  portmap mapping delete 123\n" +
+			"  portmap mapping delete --config-id 45 --protocol http --hostname 'test-*' --yes\n" +
+			"  portmap mapping list --output json | jq '.data[].id' | portmap mapping delete - --yes",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 || args[0] == "-" {
+				return runBulkDelete(cmd, args, filter, yes, parallel)
+			}
+			if !filter.empty() {
+				return fmt.Errorf("a mapping ID can't be combined with --config-id, --protocol or --hostname")
+			}
+
 			token := cmd.Flag("token").Value.String()
 			outputFormat := cmd.Flag("output").Value.String()
 
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
 			// Add validation before processing
 			if valid, msg := validation.IsValidID(args[0]); !valid {
 				return fmt.Errorf("invalid mapping ID: %s", msg)
 			}
 
-			// First get mapping details to determine region
+			// First get mapping details to determine region, as they are now
+			api.SetCacheTTL(0)
 			client := api.NewClient(token)
 			mapping, err := client.GetMapping(args[0])
 			if err != nil {
 				return err
 			}
 			// Extract region from response
+			var data map[string]interface{}
 			if response, ok := mapping.(map[string]interface{}); ok {
-				if data, ok := response["data"].(map[string]interface{}); ok {
+				if data, ok = response["data"].(map[string]interface{}); ok {
 					if config, ok := data["config"].(map[string]interface{}); ok {
 						if region, ok := config["region"].(string); ok && region != "" && region != "default" {
 							// Create new client with region-specific domain
-							baseURL := fmt.Sprintf("https://%s/api", common.GetDomainWithRegion(region))
+							baseURL := regions.APIURL(region)
 							client = api.NewClientWithBaseURL(token, baseURL)
 						}
 					}
 				}
 			}
 
+			if cmd.Flag("dry-run").Value.String() == "true" {
+				return output.PrintDryRun("DELETE", "/mappings/"+args[0], nil, format)
+			}
+
+			if !yes {
+				configName := ""
+				if config, ok := data["config"].(map[string]interface{}); ok {
+					configName = fmt.Sprintf(" of config %v", config["name"])
+				}
+				fmt.Fprintf(os.Stderr, "Mapping %s %v://%v:%v -> %v%s will be deleted.\n",
+					args[0], data["protocol"], data["hostname"], data["port_from"], data["port_to"], configName)
+
+				confirmed, err := input.Confirm("Delete?")
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return fmt.Errorf("aborted")
+				}
+			}
+
 			// Delete the mapping using appropriate client
 			if err := client.DeleteMapping(args[0]); err != nil {
 				return err
 			}
 
-			if outputFormat == "text" {
+			if format == output.Text {
 				fmt.Println("Mapping deleted successfully")
 				return nil
 			}
 
 			opts := output.Options{
-				Format: "json",
+				Format: format,
+				Kind:   output.KindStatus,
 			}
 			return output.Print(map[string]string{
 				"status":  "success",
@@ -549,5 +596,47 @@ func newDeleteCommand() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.Flags().StringVar(&filter.configID, "config-id", "", "Delete the mappings of this configuration")
+	cmd.Flags().StringVar(&filter.protocol, "protocol", "", "Delete the mappings with this protocol (tcp, udp, http, https)")
+	cmd.Flags().StringVar(&filter.hostname, "hostname", "", "Delete the mappings whose hostname matches this pattern, e.g. 'test-*'")
+	cmd.Flags().IntVar(&parallel, "parallel", 4, "Number of mappings deleted at the same time")
+	cmd.RegisterFlagCompletionFunc("config-id", completion.ConfigIDs)
+	cmd.RegisterFlagCompletionFunc("protocol", completion.Protocols)
+
 	return cmd
 }
+
+// validWSTimeout checks a WebSocket timeout answer
+func validWSTimeout(value string) (bool, string) {
+	timeout, err := strconv.Atoi(value)
+	if err != nil {
+		return false, "Timeout must be a number"
+	}
+	return validation.IsValidWSTimeout(timeout)
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+// configTypeOf returns the type of the config with id in a ListConfigs response
+func configTypeOf(configs []interface{}, id string) string {
+	return configField(configs, id, "type")
+}
+
+func configNameOf(configs []interface{}, id string) string {
+	return configField(configs, id, "name")
+}
+
+func configField(configs []interface{}, id, field string) string {
+	for _, conf := range configs {
+		if config, ok := conf.(map[string]interface{}); ok && api.FormatID(config["id"]) == id {
+			return fmt.Sprintf("%v", config[field])
+		}
+	}
+	return ""
+}
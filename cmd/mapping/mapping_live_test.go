@@ -39,11 +39,12 @@ func TestMappingLifecycle(t *testing.T) {
 	// Add persistent flags to both commands
 	for _, cmd := range []*cobra.Command{mappingCmd, configCmd} {
 		cmd.PersistentFlags().String("token", config.Token, "API token")
-		cmd.PersistentFlags().String("output", "json", "Output format")
+		cmd.PersistentFlags().String("output", "raw", "Output format")
+		cmd.PersistentFlags().Bool("dry-run", false, "Dry run")
 		cmd.PersistentFlags().String("env-file", "../../.env", "Path to env file")
 
 		require.NoError(t, cmd.PersistentFlags().Set("token", config.Token))
-		require.NoError(t, cmd.PersistentFlags().Set("output", "json"))
+		require.NoError(t, cmd.PersistentFlags().Set("output", "raw"))
 		require.NoError(t, cmd.PersistentFlags().Set("env-file", "../../.env"))
 	}
 
@@ -84,10 +85,10 @@ func TestMappingLifecycle(t *testing.T) {
 			// Register cleanup handlers
 			t.Cleanup(func() {
 				if mappingID != "" {
-					_, _ = testutil.ExecuteCommand(mappingCmd, "delete", mappingID)
+					_, _ = testutil.ExecuteCommand(mappingCmd, "delete", mappingID, "--yes")
 				}
 				if configID != "" {
-					_, _ = testutil.ExecuteCommand(configCmd, "delete", configID)
+					_, _ = testutil.ExecuteCommand(configCmd, "delete", configID, "--yes")
 				}
 			})
 
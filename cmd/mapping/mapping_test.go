@@ -0,0 +1,18 @@
+package mapping
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigField(t *testing.T) {
+	var configs []interface{}
+	require.NoError(t, json.Unmarshal([]byte(`[{"id": 12345678, "name": "office", "type": "WireGuard"}]`), &configs))
+
+	assert.Equal(t, "WireGuard", configTypeOf(configs, "12345678"))
+	assert.Equal(t, "office", configNameOf(configs, "12345678"))
+	assert.Equal(t, "", configTypeOf(configs, "1.2345678e+07"))
+}
@@ -0,0 +1,128 @@
+package regions
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+)
+
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "regions",
+		Short: "Show portmap.io regions",
+	}
+
+	cmd.AddCommand(
+		newListCommand(),
+		newProbeCommand(),
+	)
+
+	return cmd
+}
+
+func newListCommand() *cobra.Command {
+	var refresh bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List available regions",
+		Long:  "List the regions portmap.io serves. The catalog is fetched from the API and cached\nfor a day; use --refresh to fetch it right away.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := cmd.Flag("token").Value.String()
+			outputFormat := cmd.Flag("output").Value.String()
+
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			if refresh {
+				if err := regions.Refresh(api.NewClient(token), true); err != nil {
+					return err
+				}
+			}
+
+			list := regions.All()
+			if format == output.Text {
+				w := tabwriter.NewWriter(output.GetWriter(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "NAME\tHOSTNAME\tLABEL")
+				fmt.Fprintln(w, "----\t--------\t-----")
+				for _, r := range list {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Hostname, r.Label)
+				}
+				return w.Flush()
+			}
+
+			return output.Print(map[string]interface{}{"data": list}, output.Options{Format: format, Kind: output.KindRegionList})
+		},
+	}
+
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Fetch the region catalog from the API instead of using the cache")
+
+	return cmd
+}
+
+func newProbeCommand() *cobra.Command {
+	opts := regions.DefaultProbeOptions
+
+	cmd := &cobra.Command{
+		Use:   "probe",
+		Short: "Measure the round-trip time to every region",
+		Long:  "Measure the round-trip time to each region's endpoint concurrently and rank the\nregions fastest first. TCP times the connect handshake to port 443; UDP times the\nport unreachable reply to a datagram.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFormat := cmd.Flag("output").Value.String()
+
+			format, err := output.ParseFormat(outputFormat)
+			if err != nil {
+				return err
+			}
+
+			results, err := regions.Probe(regions.All(), opts)
+			if err != nil {
+				return err
+			}
+
+			if format == output.Text {
+				w := tabwriter.NewWriter(output.GetWriter(), 0, 0, 2, ' ', 0)
+				fmt.Fprintln(w, "RANK\tREGION\tHOSTNAME\tRTT")
+				fmt.Fprintln(w, "----\t------\t--------\t---")
+				for i, r := range results {
+					rtt := r.RTT.Round(100 * time.Microsecond).String()
+					if r.Err != nil {
+						rtt = "unreachable"
+					}
+					fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, r.Region.Name, r.Region.Hostname, rtt)
+				}
+				return w.Flush()
+			}
+
+			data := make([]interface{}, 0, len(results))
+			for _, r := range results {
+				item := map[string]interface{}{
+					"region":   r.Region.Name,
+					"hostname": r.Region.Hostname,
+				}
+				if r.Err != nil {
+					item["error"] = r.Err.Error()
+				} else {
+					item["rtt_ms"] = float64(r.RTT.Microseconds()) / 1000
+				}
+				data = append(data, item)
+			}
+			return output.Print(map[string]interface{}{"data": data}, output.Options{Format: format, Kind: output.KindProbeResultList})
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Network, "protocol", opts.Network, "Probe protocol (tcp, udp)")
+	cmd.Flags().StringVar(&opts.Port, "port", "", "Port to probe (default: 443 for tcp, 33434 for udp)")
+	cmd.Flags().IntVar(&opts.Attempts, "count", opts.Attempts, "Round trips per region; the fastest one counts")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", opts.Timeout, "Time to wait for each round trip")
+
+	return cmd
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheTTLEnvVar overrides how long GET responses are served from the cache
+// without asking the API; "0" revalidates every request
+const CacheTTLEnvVar = "PORTMAP_CACHE_TTL"
+
+// DefaultCacheTTL keeps repeated calls within one command, and commands run in
+// quick succession, off the network
+const DefaultCacheTTL = 30 * time.Second
+
+var offline bool
+
+// ttlOverride replaces the configured cache TTL when it is not negative
+var ttlOverride time.Duration = -1
+
+// SetOffline makes GET requests fall back to the last cached response, however
+// old, when the API can't be reached
+func SetOffline(enabled bool) {
+	offline = enabled
+}
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	ETag      string          `json:"etag,omitempty"`
+	Body      json.RawMessage `json:"body"`
+}
+
+func newHTTPClient() *http.Client {
+	// Offline mode should give up on an unreachable API quickly
+	if offline {
+		return &http.Client{Timeout: 5 * time.Second}
+	}
+	return &http.Client{}
+}
+
+// SetCacheTTL overrides how long GET responses are served from the cache, for
+// commands that poll or decide on changes, and need every request to reach the API
+func SetCacheTTL(ttl time.Duration) {
+	ttlOverride = ttl
+}
+
+func cacheTTL() time.Duration {
+	if ttlOverride >= 0 {
+		return ttlOverride
+	}
+	if value := os.Getenv(CacheTTLEnvVar); value != "" {
+		if ttl, err := time.ParseDuration(value); err == nil {
+			return ttl
+		}
+	}
+	return DefaultCacheTTL
+}
+
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// cacheDir holds the cached responses of one account, from the default and
+// the regional APIs alike, so a change sent to any of them clears them all
+func (c *RealClient) cacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "portmap", "api", hash(c.token)), nil
+}
+
+// cacheFile is where the response to path from the API of c is kept
+func (c *RealClient) cacheFile(dir, path string) string {
+	return filepath.Join(dir, hash(c.baseURL+path)+".json")
+}
+
+func (c *RealClient) readCache(path string) *cacheEntry {
+	dir, err := c.cacheDir()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(c.cacheFile(dir, path))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	return &entry
+}
+
+// writeCache stores entry; failing to cache only costs a later request
+func (c *RealClient) writeCache(path string, entry *cacheEntry) {
+	dir, err := c.cacheDir()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
+	os.WriteFile(c.cacheFile(dir, path), data, 0600)
+}
+
+func (c *RealClient) invalidateCache() {
+	if dir, err := c.cacheDir(); err == nil {
+		os.RemoveAll(dir)
+	}
+}
+
+// cachedGet serves path from the cache while it is fresh, revalidates it with
+// its ETag once it is not, and in offline mode falls back to it when the API
+// is unreachable
+func (c *RealClient) cachedGet(path string) ([]byte, error) {
+	entry := c.readCache(path)
+	if entry != nil && time.Since(entry.FetchedAt) < cacheTTL() {
+		return entry.Body, nil
+	}
+
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, data, err := c.execute(req)
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		err = fmt.Errorf("API error: %s", string(data))
+	}
+	if err != nil {
+		if offline && entry != nil {
+			warnCached(entry)
+			return entry.Body, nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		entry.FetchedAt = time.Now()
+		c.writeCache(path, entry)
+		return entry.Body, nil
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("API error: %s", string(data))
+	}
+
+	if json.Valid(data) {
+		c.writeCache(path, &cacheEntry{FetchedAt: time.Now(), ETag: resp.Header.Get("ETag"), Body: data})
+	}
+	return data, nil
+}
+
+// warnCached tells that the API was unreachable and entry is shown instead
+func warnCached(entry *cacheEntry) {
+	fmt.Fprintf(os.Stderr, "Warning: API unreachable, showing data cached at %s\n", entry.FetchedAt.Local().Format(time.RFC3339))
+}
+
+// stream is cachedGet for responses that are read as they arrive. Cached
+// bodies are served the same way; a new body is cached once it has been read
+// to the end.
+func (c *RealClient) stream(path string) (io.ReadCloser, error) {
+	entry := c.readCache(path)
+	if entry != nil && time.Since(entry.FetchedAt) < cacheTTL() {
+		return io.NopCloser(bytes.NewReader(entry.Body)), nil
+	}
+
+	req, err := c.newRequest(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if entry != nil && entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to execute request: %w", err)
+	} else if resp.StatusCode >= http.StatusInternalServerError {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		err = fmt.Errorf("API error: %s", string(data))
+	}
+	if err != nil {
+		if offline && entry != nil {
+			warnCached(entry)
+			return io.NopCloser(bytes.NewReader(entry.Body)), nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		entry.FetchedAt = time.Now()
+		c.writeCache(path, entry)
+		return io.NopCloser(bytes.NewReader(entry.Body)), nil
+	}
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("API error: %s", string(data))
+	}
+	return &cachingBody{ReadCloser: resp.Body, client: c, path: path, etag: resp.Header.Get("ETag")}, nil
+}
+
+// cachingBody passes a response body through, keeping a copy to cache once
+// the end is reached
+type cachingBody struct {
+	io.ReadCloser
+	client *RealClient
+	path   string
+	etag   string
+	copy   bytes.Buffer
+	cached bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.copy.Write(p[:n])
+	if err == io.EOF && !b.cached && json.Valid(b.copy.Bytes()) {
+		b.cached = true
+		b.client.writeCache(b.path, &cacheEntry{FetchedAt: time.Now(), ETag: b.etag, Body: b.copy.Bytes()})
+	}
+	return n, err
+}
+
+// Close reads what a decoder left after the JSON value, usually a newline, so
+// the body still gets cached
+func (b *cachingBody) Close() error {
+	io.Copy(io.Discard, b)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedGet(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var gets, revalidated int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			gets++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				revalidated++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`{"data":[{"id":1}]}`))
+		case http.MethodDelete:
+			w.Write([]byte(`{"status":"success"}`))
+		}
+	}))
+	client := NewClientWithBaseURL("token", server.URL)
+
+	// Fresh entries are served without a request
+	t.Setenv(CacheTTLEnvVar, "1h")
+	_, err := client.ListConfigs(map[string]string{"type": "WireGuard", "region": "fra1"})
+	require.NoError(t, err)
+	_, err = client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, gets)
+
+	// Stale entries are revalidated with their ETag
+	t.Setenv(CacheTTLEnvVar, "0")
+	configs, err := client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, gets)
+	assert.Equal(t, 1, revalidated)
+	assert.Len(t, configs.(map[string]interface{})["data"], 1)
+
+	// Mutations drop the cache
+	t.Setenv(CacheTTLEnvVar, "1h")
+	require.NoError(t, client.DeleteConfig("1"))
+	_, err = client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, gets)
+	assert.Equal(t, 1, revalidated)
+
+	// So do mutations sent to the API of a region
+	regional := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer regional.Close()
+	require.NoError(t, NewClientWithBaseURL("token", regional.URL).DeleteMapping("2"))
+	_, err = client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, gets)
+
+	// Offline mode falls back to the last response once the API is gone
+	server.Close()
+	t.Setenv(CacheTTLEnvVar, "0")
+	_, err = client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	assert.Error(t, err)
+
+	SetOffline(true)
+	defer SetOffline(false)
+	configs, err = client.ListConfigs(map[string]string{"region": "fra1", "type": "WireGuard"})
+	require.NoError(t, err)
+	assert.Len(t, configs.(map[string]interface{})["data"], 1)
+}
+
+func TestStream(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv(CacheTTLEnvVar, "1h")
+
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		if r.URL.Query().Get("config_id") == "404" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":1}]}` + "\n"))
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL("token", server.URL).(ListStreamer)
+
+	// The body is cached once the decoder is done with it
+	body, err := client.StreamMappings(map[string]string{})
+	require.NoError(t, err)
+	var response interface{}
+	require.NoError(t, json.NewDecoder(body).Decode(&response))
+	require.NoError(t, body.Close())
+
+	body, err = client.StreamMappings(map[string]string{})
+	require.NoError(t, err)
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":[{"id":1}]}`, string(data))
+	assert.Equal(t, 1, gets)
+
+	_, err = client.StreamMappings(map[string]string{"config_id": "404"})
+	assert.ErrorContains(t, err, "not found")
+}
@@ -6,6 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Client interface defines the API contract
@@ -14,10 +19,21 @@ type Client interface {
 	ListConfigs(params map[string]string) (interface{}, error)
 	GetConfig(id string) (interface{}, error)
 	DeleteConfig(id string) error
+	RotateConfigKey(id string, publicKey string) (interface{}, error)
 	CreateMapping(req MappingRequest) (interface{}, error)
+	UpdateMapping(id string, req MappingRequest) (interface{}, error)
 	ListMappings(params map[string]string) (interface{}, error)
 	GetMapping(id string) (interface{}, error)
 	DeleteMapping(id string) error
+	GetAccount() (interface{}, error)
+	ListRegions() (interface{}, error)
+}
+
+// ListStreamer is implemented by clients that can hand list responses over as
+// they arrive, so output can be written item by item
+type ListStreamer interface {
+	StreamConfigs(params map[string]string) (io.ReadCloser, error)
+	StreamMappings(params map[string]string) (io.ReadCloser, error)
 }
 
 // RealClient implements the Client interface
@@ -29,18 +45,31 @@ type RealClient struct {
 
 var testClient Client
 
+// DefaultBaseURL is the API used by NewClient unless overridden with SetBaseURL
+const DefaultBaseURL = "https://portmap.io/api"
+
+var baseURL = DefaultBaseURL
+
 func SetClient(client Client) {
 	testClient = client
 }
 
+// SetBaseURL overrides the API used by NewClient, e.g. from a profile's api_url
+func SetBaseURL(url string) {
+	if url == "" {
+		url = DefaultBaseURL
+	}
+	baseURL = strings.TrimRight(url, "/")
+}
+
 func NewClient(token string) Client {
 	if testClient != nil {
 		return testClient
 	}
 	return &RealClient{
-		baseURL:    "https://portmap.io/api",
+		baseURL:    baseURL,
 		token:      token,
-		httpClient: &http.Client{},
+		httpClient: newHTTPClient(),
 	}
 }
 
@@ -51,11 +80,54 @@ func NewClientWithBaseURL(token string, baseURL string) Client {
 	return &RealClient{
 		baseURL:    baseURL,
 		token:      token,
-		httpClient: &http.Client{},
+		httpClient: newHTTPClient(),
 	}
 }
 
-func (c *RealClient) doRequest(method, path string, body interface{}) ([]byte, error) {
+// NewClientWithTimeout returns a client whose requests give up after timeout,
+// for calls that are not worth waiting on
+func NewClientWithTimeout(token string, timeout time.Duration) Client {
+	if testClient != nil {
+		return testClient
+	}
+	return &RealClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// withQuery appends the non-empty params to path in a stable order, so equal
+// queries share a cache entry
+func withQuery(path string, params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return path
+	}
+	sort.Strings(keys)
+
+	query := url.Values{}
+	for _, k := range keys {
+		query.Set(k, params[k])
+	}
+	return path + "?" + query.Encode()
+}
+
+// FormatID formats an ID of a response. JSON numbers decode as float64, which
+// %v prints with an exponent from 1e+06 on.
+func FormatID(value interface{}) string {
+	if id, ok := value.(float64); ok {
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (c *RealClient) newRequest(method, path string, body interface{}) (*http.Request, error) {
 	var buf bytes.Buffer
 	if body != nil {
 		if err := json.NewEncoder(&buf).Encode(body); err != nil {
@@ -70,27 +142,49 @@ func (c *RealClient) doRequest(method, path string, body interface{}) ([]byte, e
 
 	req.Header.Set("Authorization", "Bearer "+c.token)
 	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
 
+// execute sends req and reads the whole response; only transport failures are errors
+func (c *RealClient) execute(req *http.Request) (*http.Response, []byte, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return resp, data, nil
+}
+
+func (c *RealClient) doRequest(method, path string, body interface{}) ([]byte, error) {
+	req, err := c.newRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, data, err := c.execute(req)
+	if err != nil {
+		return nil, err
 	}
 
 	if resp.StatusCode >= 400 {
 		return nil, fmt.Errorf("API error: %s", string(data))
 	}
 
+	// Anything changed on the server makes cached responses of this account stale
+	if method != http.MethodGet {
+		c.invalidateCache()
+	}
+
 	return data, nil
 }
 
 func (c *RealClient) get(path string) (interface{}, error) {
-	data, err := c.doRequest("GET", path, nil)
+	data, err := c.cachedGet(path)
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +211,20 @@ func (c *RealClient) post(path string, body interface{}) (interface{}, error) {
 	return result, nil
 }
 
+func (c *RealClient) put(path string, body interface{}) (interface{}, error) {
+	data, err := c.doRequest("PUT", path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return result, nil
+}
+
 func (c *RealClient) delete(path string) (interface{}, error) {
 	data, err := c.doRequest("DELETE", path, nil)
 	if err != nil {
@@ -131,18 +239,12 @@ func (c *RealClient) delete(path string) (interface{}, error) {
 	return result, nil
 }
 
-// Add this helper function
-func getDomainPrefix(region string) string {
-	if region != "" && region != "default" {
-		return fmt.Sprintf("%s.", region)
-	}
-	return ""
+// GetAccount returns the account the token belongs to
+func (c *RealClient) GetAccount() (interface{}, error) {
+	return c.get("/account")
 }
 
-// Add helper function to get domain with region prefix
-func getDomainWithRegion(region string) string {
-	if region != "" && region != "default" {
-		return fmt.Sprintf("%s.portmap.io", region)
-	}
-	return "portmap.io"
+// ListRegions returns the regions portmap.io currently serves
+func (c *RealClient) ListRegions() (interface{}, error) {
+	return c.get("/regions")
 }
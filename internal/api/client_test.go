@@ -0,0 +1,16 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatID(t *testing.T) {
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 12345678, "config_id": "42"}`), &response))
+	assert.Equal(t, "12345678", FormatID(response["id"]))
+	assert.Equal(t, "42", FormatID(response["config_id"]))
+}
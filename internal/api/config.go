@@ -1,9 +1,6 @@
 package api
 
-import (
-	"fmt"
-	"strings"
-)
+import "io"
 
 type ConfigRequest struct {
 	Name         string `json:"name"`
@@ -11,6 +8,7 @@ type ConfigRequest struct {
 	OpenvpnProto string `json:"openvpn_proto"`
 	Region       string `json:"region"`
 	Comment      string `json:"comment,omitempty"`
+	PublicKey    string `json:"public_key,omitempty"`
 }
 
 func (c *RealClient) CreateConfig(req ConfigRequest) (interface{}, error) {
@@ -18,22 +16,12 @@ func (c *RealClient) CreateConfig(req ConfigRequest) (interface{}, error) {
 }
 
 func (c *RealClient) ListConfigs(params map[string]string) (interface{}, error) {
-	url := "/configs"
-
-	// Add query parameters to URL
-	if len(params) > 0 {
-		query := make([]string, 0, len(params))
-		for k, v := range params {
-			if v != "" { // Only add non-empty parameters
-				query = append(query, fmt.Sprintf("%s=%s", k, v))
-			}
-		}
-		if len(query) > 0 {
-			url += "?" + strings.Join(query, "&")
-		}
-	}
-
-	return c.get(url)
+	return c.get(withQuery("/configs", params))
+}
+
+// StreamConfigs is ListConfigs as the undecoded response body
+func (c *RealClient) StreamConfigs(params map[string]string) (io.ReadCloser, error) {
+	return c.stream(withQuery("/configs", params))
 }
 
 func (c *RealClient) GetConfig(id string) (interface{}, error) {
@@ -44,3 +32,10 @@ func (c *RealClient) DeleteConfig(id string) error {
 	_, err := c.delete("/configs/" + id)
 	return err
 }
+
+// RotateConfigKey registers a new WireGuard public key for a config
+func (c *RealClient) RotateConfigKey(id string, publicKey string) (interface{}, error) {
+	return c.post("/configs/"+id+"/rotate-key", map[string]string{
+		"public_key": publicKey,
+	})
+}
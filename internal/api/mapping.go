@@ -1,9 +1,6 @@
 package api
 
-import (
-	"fmt"
-	"strings"
-)
+import "io"
 
 type MappingRequest struct {
 	Hostname        string `json:"hostname"`
@@ -23,6 +20,7 @@ type MappingClient interface {
 	ListMappings(params map[string]string) (interface{}, error)
 	GetMapping(id string) (interface{}, error)
 	CreateMapping(req MappingRequest) (interface{}, error)
+	UpdateMapping(id string, req MappingRequest) (interface{}, error)
 	DeleteMapping(id string) error
 }
 
@@ -30,23 +28,18 @@ func (c *RealClient) CreateMapping(req MappingRequest) (interface{}, error) {
 	return c.post("/mappings", req)
 }
 
+// UpdateMapping replaces the settings of an existing mapping
+func (c *RealClient) UpdateMapping(id string, req MappingRequest) (interface{}, error) {
+	return c.put("/mappings/"+id, req)
+}
+
 func (c *RealClient) ListMappings(params map[string]string) (interface{}, error) {
-	url := "/mappings"
-
-	// Add query parameters
-	if len(params) > 0 {
-		query := make([]string, 0, len(params))
-		for k, v := range params {
-			if v != "" {
-				query = append(query, fmt.Sprintf("%s=%s", k, v))
-			}
-		}
-		if len(query) > 0 {
-			url += "?" + strings.Join(query, "&")
-		}
-	}
-
-	return c.get(url)
+	return c.get(withQuery("/mappings", params))
+}
+
+// StreamMappings is ListMappings as the undecoded response body
+func (c *RealClient) StreamMappings(params map[string]string) (io.ReadCloser, error) {
+	return c.stream(withQuery("/mappings", params))
 }
 
 func (c *RealClient) GetMapping(id string) (interface{}, error) {
@@ -0,0 +1,163 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/output"
+	"portmap.io/client/internal/regions"
+	"portmap.io/client/pkg/config"
+)
+
+// Regions completes region names from the region catalog
+func Regions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, r := range regions.All() {
+		if !strings.HasPrefix(r.Name, toComplete) {
+			continue
+		}
+		if r.Label != "" {
+			names = append(names, r.Name+"\t"+r.Label)
+		} else {
+			names = append(names, r.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// ConfigTypes completes configuration types
+func ConfigTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return cobra.FixedCompletions([]string{"OpenVPN", "SSH", "WireGuard"}, cobra.ShellCompDirectiveNoFileComp)(cmd, args, toComplete)
+}
+
+// Protocols completes mapping protocols
+func Protocols(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return cobra.FixedCompletions([]string{"tcp", "udp", "http", "https"}, cobra.ShellCompDirectiveNoFileComp)(cmd, args, toComplete)
+}
+
+// OpenVPNProtocols completes the transport protocols of OpenVPN configurations
+func OpenVPNProtocols(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return cobra.FixedCompletions([]string{"tcp", "udp"}, cobra.ShellCompDirectiveNoFileComp)(cmd, args, toComplete)
+}
+
+// Columns completes the columns of a list command, after any columns already
+// given in a comma separated list
+func Columns(columns []output.Column) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		prefix := ""
+		if i := strings.LastIndex(toComplete, ","); i >= 0 {
+			prefix = toComplete[:i+1]
+		}
+		var names []string
+		for _, c := range columns {
+			if strings.HasPrefix(prefix+c.Name, toComplete) {
+				names = append(names, prefix+c.Name+"\t"+c.Description)
+			}
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// ConfigIDs completes configuration IDs, annotated with name, type and region.
+// It serves both the config-id argument and the --config-id flag.
+func ConfigIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	items, err := list(cmd, func(client api.Client) (interface{}, error) {
+		return client.ListConfigs(map[string]string{})
+	})
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var ids []string
+	for _, item := range items {
+		id := api.FormatID(item["id"])
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("%s\t%v (%v, %v)", id, item["name"], item["type"], item["region"]))
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// MappingIDs completes mapping IDs, annotated with hostname, port and region
+func MappingIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	items, err := list(cmd, func(client api.Client) (interface{}, error) {
+		return client.ListMappings(map[string]string{})
+	})
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var ids []string
+	for _, item := range items {
+		id := api.FormatID(item["id"])
+		if !strings.HasPrefix(id, toComplete) {
+			continue
+		}
+		// The region of a mapping is that of its config
+		region, _ := item["region"].(string)
+		if config, ok := item["config"].(map[string]interface{}); ok {
+			if r, ok := config["region"].(string); ok && r != "" {
+				region = r
+			}
+		}
+		ids = append(ids, fmt.Sprintf("%s\t%v:%v (%s)", id, item["hostname"], api.FormatID(item["port_from"]), region))
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// list returns the items of a list response. Completion runs without the root
+// command's pre-run, so the config is loaded here from the flags the command
+// was given. Responses come from the API cache, so repeated tab presses stay
+// off the network.
+func list(cmd *cobra.Command, fetch func(api.Client) (interface{}, error)) ([]map[string]interface{}, error) {
+	if flag := cmd.Flag("profile"); flag != nil {
+		config.SetProfile(flag.Value.String())
+	}
+	envFile := ""
+	if flag := cmd.Flag("env-file"); flag != nil {
+		envFile = flag.Value.String()
+	}
+
+	cfg, err := config.LoadConfig(envFile)
+	if err != nil {
+		return nil, err
+	}
+	token := cfg.Token
+	if flag := cmd.Flag("token"); flag != nil && flag.Changed {
+		token = flag.Value.String()
+	}
+	if token == "" {
+		return nil, fmt.Errorf("API token not found")
+	}
+	api.SetBaseURL(cfg.APIURL)
+
+	response, err := fetch(api.NewClient(token))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []map[string]interface{}
+	if wrapper, ok := response.(map[string]interface{}); ok {
+		if data, ok := wrapper["data"].([]interface{}); ok {
+			for _, entry := range data {
+				if item, ok := entry.(map[string]interface{}); ok {
+					items = append(items, item)
+				}
+			}
+		}
+	}
+	return items, nil
+}
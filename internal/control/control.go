@@ -0,0 +1,105 @@
+package control
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Commands understood by a running connect session
+const (
+	Reload = "reload"
+	Status = "status"
+)
+
+// Handler runs a control command and returns a short message for the caller
+type Handler func(command string) (string, error)
+
+// SocketPath returns the control socket of the connect session for configID
+func SocketPath(configID string) string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, fmt.Sprintf("portmap-%s.sock", configID))
+}
+
+// Listen serves control commands for configID until the returned closer is closed
+func Listen(configID string, handler Handler) (io.Closer, error) {
+	path := SocketPath(configID)
+
+	// A socket left behind by a crashed session would make the listen fail
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another connect session for config %s is already running", configID)
+	}
+	os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open control socket: %v", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to secure control socket: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn, handler)
+		}
+	}()
+
+	return listener, nil
+}
+
+func serve(conn net.Conn, handler Handler) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+
+	msg, err := handler(strings.TrimSpace(line))
+	if err != nil {
+		fmt.Fprintf(conn, "error %s\n", err)
+		return
+	}
+	fmt.Fprintf(conn, "ok %s\n", msg)
+}
+
+// Send runs command in the connect session for configID and returns its reply
+func Send(configID, command string) (string, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(configID), 2*time.Second)
+	if err != nil {
+		return "", fmt.Errorf("no running connect session for config %s", configID)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	if _, err := fmt.Fprintf(conn, "%s\n", command); err != nil {
+		return "", fmt.Errorf("failed to send %s: %v", command, err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("failed to read reply: %v", err)
+	}
+
+	status, msg, _ := strings.Cut(strings.TrimSpace(reply), " ")
+	if status != "ok" {
+		return "", fmt.Errorf("%s failed: %s", command, msg)
+	}
+	return msg, nil
+}
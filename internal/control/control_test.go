@@ -0,0 +1,35 @@
+package control
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenAndSend(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	_, err := Send("42", Reload)
+	assert.EqualError(t, err, "no running connect session for config 42")
+
+	listener, err := Listen("42", func(command string) (string, error) {
+		if command == Reload {
+			return "private key reloaded", nil
+		}
+		return "", fmt.Errorf("unknown command %s", command)
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	_, err = Listen("42", nil)
+	assert.EqualError(t, err, "another connect session for config 42 is already running")
+
+	msg, err := Send("42", Reload)
+	require.NoError(t, err)
+	assert.Equal(t, "private key reloaded", msg)
+
+	_, err = Send("42", "bogus")
+	assert.EqualError(t, err, "bogus failed: unknown command bogus")
+}
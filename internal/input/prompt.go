@@ -1,23 +1,87 @@
 package input
 
 import (
-    "bufio"
-    "fmt"
-    "strings"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
 )
 
+var noInput bool
+
+// SetNoInput turns all prompts off, as --no-input does
+func SetNoInput(disabled bool) {
+	noInput = disabled
+}
+
+// Interactive reports whether questions can be asked: stdin is a terminal and
+// --no-input is not set
+func Interactive() bool {
+	return !noInput && IsTerminal()
+}
+
+// notInteractive explains why nothing can be asked
+func notInteractive() string {
+	if noInput {
+		return "--no-input is set"
+	}
+	return "stdin is not a terminal"
+}
+
 // PromptForValue prompts the user for input with optional requirement
 func PromptForValue(reader *bufio.Reader, prompt string, required bool) (string, error) {
-    for {
-        fmt.Printf("%s: ", prompt)
-        value, err := reader.ReadString('\n')
-        if err != nil {
-            return "", err
-        }
-        value = strings.TrimSpace(value)
-        if value != "" || !required {
-            return value, nil
-        }
-        fmt.Println("This field is required")
-    }
+	if noInput {
+		return "", fmt.Errorf("cannot ask for %s: --no-input is set", strings.ToLower(prompt))
+	}
+	for {
+		fmt.Printf("%s: ", prompt)
+		value, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		value = strings.TrimSpace(value)
+		if value != "" || !required {
+			return value, nil
+		}
+		fmt.Println("This field is required")
+	}
+}
+
+// IsTerminal reports whether stdin is attached to a terminal
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadSecret prompts for a value without echoing it back to the terminal
+func ReadSecret(prompt string) (string, error) {
+	if !Interactive() {
+		return "", fmt.Errorf("cannot read %s: %s", strings.ToLower(prompt), notInteractive())
+	}
+
+	fmt.Printf("%s: ", prompt)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// Confirm asks a yes/no question on stderr, defaulting to no. Without a terminal
+// or with --no-input there is nobody to ask, so it fails and the caller's --yes
+// has to be used.
+func Confirm(question string) (bool, error) {
+	if !Interactive() {
+		return false, fmt.Errorf("confirmation required but %s, use --yes to skip it", notInteractive())
+	}
+
+	fmt.Fprintf(os.Stderr, "%s (y/N): ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
 }
@@ -0,0 +1,321 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+)
+
+// ErrInterrupted is returned when Ctrl+C is pressed in a select list, which
+// reads keys in raw mode where it doesn't send an interrupt
+var ErrInterrupted = errors.New("interrupted")
+
+// Validator checks an answer, in the form of the validation.IsValid* functions
+type Validator func(value string) (bool, string)
+
+// Option is a choice of a select list. Hint is shown next to the label.
+type Option struct {
+	Label string
+	Value string
+	Hint  string
+}
+
+// Options turns values into options labeled with themselves
+func Options(values ...string) []Option {
+	options := make([]Option, len(values))
+	for i, v := range values {
+		options[i] = Option{Label: v, Value: v}
+	}
+	return options
+}
+
+// Field is a line of a review screen
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Prompter asks questions on stderr, so they don't mix with the command's
+// output. On a terminal select lists are navigated with the arrow keys;
+// otherwise every question reads a line.
+type Prompter struct {
+	in       *bufio.Reader
+	out      io.Writer
+	tty      bool
+	disabled bool
+	asked    int
+}
+
+// NewPrompter returns a Prompter reading from stdin. When stdin is not a
+// terminal or --no-input is set it asks nothing: commands check Interactive
+// and report what is missing instead.
+func NewPrompter() *Prompter {
+	return &Prompter{in: bufio.NewReader(os.Stdin), out: os.Stderr, tty: IsTerminal(), disabled: !Interactive()}
+}
+
+// Interactive reports whether the Prompter can ask questions
+func (p *Prompter) Interactive() bool {
+	return !p.disabled
+}
+
+// ask counts a question, or refuses it when input is off
+func (p *Prompter) ask(question string) error {
+	if p.disabled {
+		return fmt.Errorf("cannot ask for %s: %s", strings.ToLower(question), notInteractive())
+	}
+	p.asked++
+	return nil
+}
+
+// Asked tells whether any question was asked, so a review is due
+func (p *Prompter) Asked() bool {
+	return p.asked > 0
+}
+
+const (
+	colorQuestion = "\033[36m"
+	colorError    = "\033[31m"
+	colorReset    = "\033[0m"
+)
+
+func (p *Prompter) color(color, s string) string {
+	if !p.tty {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func (p *Prompter) invalid(msg string) {
+	fmt.Fprintln(p.out, p.color(colorError, "✗ "+msg))
+}
+
+// Text asks for a value until validate accepts it. An empty answer takes
+// defaultValue, and validate decides whether empty values are allowed.
+func (p *Prompter) Text(question, defaultValue string, validate Validator) (string, error) {
+	if err := p.ask(question); err != nil {
+		return "", err
+	}
+	prompt := p.color(colorQuestion, "?") + " " + question
+	if defaultValue != "" {
+		prompt += " [" + defaultValue + "]"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s: ", prompt)
+		value, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = defaultValue
+		}
+		if validate != nil {
+			if valid, msg := validate(value); !valid {
+				p.invalid(msg)
+				continue
+			}
+		}
+		return value, nil
+	}
+}
+
+// Confirm asks a yes/no question, where an empty answer takes defaultYes
+func (p *Prompter) Confirm(question string, defaultYes bool) (bool, error) {
+	if err := p.ask(question); err != nil {
+		return false, err
+	}
+	choices := "y/N"
+	if defaultYes {
+		choices = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s %s (%s): ", p.color(colorQuestion, "?"), question, choices)
+		answer, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		p.invalid("Please answer y or n")
+	}
+}
+
+// Select asks to pick one of options and returns its value. The option with
+// defaultValue is preselected, the first one without.
+func (p *Prompter) Select(question string, options []Option, defaultValue string) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("nothing to select for %s", strings.ToLower(question))
+	}
+	if err := p.ask(question); err != nil {
+		return "", err
+	}
+	s := &selection{count: len(options)}
+	for i, o := range options {
+		if o.Value == defaultValue {
+			s.cursor = i
+		}
+	}
+
+	if p.tty {
+		if state, err := term.MakeRaw(int(os.Stdin.Fd())); err == nil {
+			defer term.Restore(int(os.Stdin.Fd()), state)
+			return p.selectKeys(question, options, s)
+		}
+	}
+	return p.selectLine(question, options, s)
+}
+
+// selectLine lists numbered options and reads a number or value
+func (p *Prompter) selectLine(question string, options []Option, s *selection) (string, error) {
+	fmt.Fprintf(p.out, "%s %s:\n", p.color(colorQuestion, "?"), question)
+	for i, o := range options {
+		fmt.Fprintf(p.out, "  %d. %s\n", i+1, label(o))
+	}
+	for {
+		fmt.Fprintf(p.out, "Number (1-%d) [%d]: ", len(options), s.cursor+1)
+		answer, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			return options[s.cursor].Value, nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1].Value, nil
+		}
+		for _, o := range options {
+			if o.Value == answer {
+				return o.Value, nil
+			}
+		}
+		p.invalid(fmt.Sprintf("Please enter a number from 1 to %d", len(options)))
+	}
+}
+
+// selectKeys draws the options below the question and moves the cursor with
+// the keys pressed, until one is picked. The list is then replaced by the answer.
+func (p *Prompter) selectKeys(question string, options []Option, s *selection) (string, error) {
+	fmt.Fprint(p.out, "\033[?25l")
+	defer fmt.Fprint(p.out, "\033[?25h")
+
+	drawn := 0
+	buf := make([]byte, 8)
+	for {
+		// Raw mode doesn't turn \n into \r\n
+		var frame strings.Builder
+		if drawn > 0 {
+			fmt.Fprintf(&frame, "\r\033[%dA", drawn)
+		}
+		frame.WriteString("\r\033[J")
+		fmt.Fprintf(&frame, "%s %s  (↑/↓ to move, enter to select)\r\n", p.color(colorQuestion, "?"), question)
+		first, last := s.window()
+		for i := first; i < last; i++ {
+			if i == s.cursor {
+				frame.WriteString(p.color(colorQuestion, "> "+label(options[i])))
+			} else {
+				frame.WriteString("  " + label(options[i]))
+			}
+			frame.WriteString("\r\n")
+		}
+		io.WriteString(p.out, frame.String())
+		drawn = last - first + 1
+
+		n, err := p.in.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		done, err := s.key(string(buf[:n]))
+		if err != nil || done {
+			fmt.Fprintf(p.out, "\r\033[%dA\033[J", drawn)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(p.out, "%s %s: %s\r\n", p.color(colorQuestion, "?"), question, options[s.cursor].Label)
+			return options[s.cursor].Value, nil
+		}
+	}
+}
+
+func label(o Option) string {
+	if o.Hint == "" {
+		return o.Label
+	}
+	return o.Label + " (" + o.Hint + ")"
+}
+
+// selectHeight is how many options a select list shows at once
+const selectHeight = 10
+
+// selection is the cursor of a select list
+type selection struct {
+	count  int
+	cursor int
+}
+
+// key moves the cursor for a key press, and tells whether an option was picked
+func (s *selection) key(k string) (bool, error) {
+	switch k {
+	case "\x1b[A", "\x1bOA", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "\x1b[B", "\x1bOB", "j":
+		if s.cursor < s.count-1 {
+			s.cursor++
+		}
+	case "\r", "\n":
+		return true, nil
+	case "\x03":
+		return false, ErrInterrupted
+	default:
+		// A number jumps to that option
+		if n, err := strconv.Atoi(k); err == nil && n >= 1 && n <= s.count {
+			s.cursor = n - 1
+		}
+	}
+	return false, nil
+}
+
+// window returns the range of options shown, scrolled to keep the cursor visible
+func (s *selection) window() (int, int) {
+	if s.count <= selectHeight {
+		return 0, s.count
+	}
+	first := s.cursor - selectHeight/2
+	first = max(0, min(first, s.count-selectHeight))
+	return first, first + selectHeight
+}
+
+// Review shows the values about to be submitted and asks whether to go on
+func (p *Prompter) Review(title string, fields []Field) (bool, error) {
+	fmt.Fprintf(p.out, "\n%s:\n", title)
+	tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	for _, f := range fields {
+		if f.Value != "" {
+			fmt.Fprintf(tw, "  %s\t%s\n", f.Name, f.Value)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(p.out)
+	return p.Confirm("Submit?", true)
+}
@@ -0,0 +1,128 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPrompter(answers string) (*Prompter, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &Prompter{in: bufio.NewReader(strings.NewReader(answers)), out: &out}, &out
+}
+
+func TestText(t *testing.T) {
+	p, out := newTestPrompter("bad\n\ngood\n")
+	validate := func(v string) (bool, string) {
+		if v != "good" {
+			return false, "Must be good"
+		}
+		return true, ""
+	}
+	value, err := p.Text("Value", "", validate)
+	require.NoError(t, err)
+	assert.Equal(t, "good", value)
+	assert.Equal(t, 2, strings.Count(out.String(), "✗ Must be good"))
+	assert.True(t, p.Asked())
+
+	p, _ = newTestPrompter("\n")
+	value, err = p.Text("Value", "fallback", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "fallback", value)
+
+	p, _ = newTestPrompter("")
+	_, err = p.Text("Value", "", nil)
+	assert.Error(t, err)
+}
+
+func TestConfirm(t *testing.T) {
+	p, out := newTestPrompter("maybe\nyes\n\n")
+	ok, err := p.Confirm("Go?", false)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Contains(t, out.String(), "Please answer y or n")
+
+	ok, err = p.Confirm("Go?", false)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSelectLine(t *testing.T) {
+	options := []Option{{Label: "tcp", Value: "tcp"}, {Label: "http", Value: "http", Hint: "web"}}
+
+	p, out := newTestPrompter("\n")
+	value, err := p.Select("Protocol", options, "http")
+	require.NoError(t, err)
+	assert.Equal(t, "http", value)
+	assert.Contains(t, out.String(), "2. http (web)")
+
+	p, out = newTestPrompter("3\ntcp\n")
+	value, err = p.Select("Protocol", options, "")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", value)
+	assert.Contains(t, out.String(), "Please enter a number from 1 to 2")
+
+	_, err = p.Select("Protocol", nil, "")
+	assert.EqualError(t, err, "nothing to select for protocol")
+}
+
+func TestSelectionKeys(t *testing.T) {
+	s := &selection{count: 3}
+	for _, k := range []string{"\x1b[B", "\x1b[B", "\x1b[B", "k"} {
+		done, err := s.key(k)
+		require.NoError(t, err)
+		assert.False(t, done)
+	}
+	assert.Equal(t, 1, s.cursor)
+
+	_, _ = s.key("3")
+	assert.Equal(t, 2, s.cursor)
+	done, err := s.key("\r")
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	_, err = s.key("\x03")
+	assert.ErrorIs(t, err, ErrInterrupted)
+}
+
+func TestSelectionWindow(t *testing.T) {
+	s := &selection{count: 30, cursor: 0}
+	first, last := s.window()
+	assert.Equal(t, []int{0, 10}, []int{first, last})
+
+	s.cursor = 29
+	first, last = s.window()
+	assert.Equal(t, []int{20, 30}, []int{first, last})
+
+	s.cursor = 15
+	first, last = s.window()
+	assert.Equal(t, []int{10, 20}, []int{first, last})
+}
+
+func TestReview(t *testing.T) {
+	p, out := newTestPrompter("n\n")
+	ok, err := p.Review("New mapping", []Field{{"Hostname", "app.portmap.io"}, {"Host header", ""}})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Contains(t, out.String(), "  Hostname  app.portmap.io\n")
+	assert.NotContains(t, out.String(), "Host header")
+}
+
+func TestPrompterDisabled(t *testing.T) {
+	p, out := newTestPrompter("answer\n")
+	p.disabled = true
+	assert.False(t, p.Interactive())
+
+	_, err := p.Text("Hostname", "", nil)
+	assert.Error(t, err)
+	_, err = p.Confirm("Go?", true)
+	assert.Error(t, err)
+	_, err = p.Select("Protocol", Options("tcp"), "")
+	assert.Error(t, err)
+	assert.Empty(t, out.String())
+	assert.False(t, p.Asked())
+}
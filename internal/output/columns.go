@@ -0,0 +1,117 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Column is a column list commands can show, sort and filter by. Default
+// columns are shown when no --columns are given.
+type Column struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// MappingColumns are the columns of mapping list
+var MappingColumns = []Column{
+	{"id", "Mapping ID", true},
+	{"region", "Region of the configuration", true},
+	{"config_id", "ID of the configuration", true},
+	{"config_name", "Name of the configuration", true},
+	{"config_type", "Type of the configuration", true},
+	{"hostname", "Hostname the mapping is reached at", true},
+	{"protocol", "Protocol (tcp, udp, http, https)", true},
+	{"port_from", "Public port", true},
+	{"port_to", "Local port traffic is forwarded to", true},
+	{"created_at", "Creation time", true},
+	{"active", "Whether the mapping is active", true},
+	{"hostheader", "Host header sent to the local service", false},
+	{"allowed_ip", "Networks allowed to connect", false},
+	{"use_custom_domain", "Whether the hostname is a custom domain", false},
+	{"websockets", "Whether WebSockets are enabled", false},
+	{"ws_timeout", "WebSocket timeout in seconds", false},
+	{"proxy_to_http", "Whether HTTPS is forwarded as plain HTTP", false},
+}
+
+// ConfigColumns are the columns of config list
+var ConfigColumns = []Column{
+	{"id", "Configuration ID", true},
+	{"region", "Region", true},
+	{"name", "Name", true},
+	{"type", "Type (OpenVPN, SSH, WireGuard)", true},
+	{"proto", "OpenVPN protocol", true},
+	{"created_at", "Creation time", true},
+	{"comment", "Comment", true},
+}
+
+// CheckColumns returns an error naming the columns that are not available
+func CheckColumns(available []Column, names []string) error {
+	var unknown []string
+	for _, name := range names {
+		if !hasColumn(available, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown column(s): %s (see --columns help)", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+func hasColumn(available []Column, name string) bool {
+	for _, c := range available {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultNames(columns []Column) []string {
+	var names []string
+	for _, c := range columns {
+		if c.Default {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// PrintColumns lists the available columns, for --columns help
+func PrintColumns(w io.Writer, available []Column) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLUMN\tDEFAULT\tDESCRIPTION")
+	for _, c := range available {
+		isDefault := ""
+		if c.Default {
+			isDefault = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, isDefault, c.Description)
+	}
+	tw.Flush()
+}
+
+// ListOptions checks the --columns, --sort-by and --filter flags of a list
+// command against its columns and returns the options printing the list
+func ListOptions(format Format, available []Column, columns []string, sortBy, filter string, noHeaders bool) (Options, error) {
+	if err := CheckColumns(available, columns); err != nil {
+		return Options{}, err
+	}
+	if err := CheckSort(sortBy, available); err != nil {
+		return Options{}, fmt.Errorf("invalid --sort-by: %w", err)
+	}
+	conditions, err := ParseFilter(filter, available)
+	if err != nil {
+		return Options{}, err
+	}
+	return Options{
+		Format:    format,
+		Columns:   columns,
+		Filter:    conditions,
+		SortBy:    sortBy,
+		NoHeaders: noHeaders,
+	}, nil
+}
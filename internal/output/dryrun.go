@@ -0,0 +1,36 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PrintDryRun shows the API request a command would have sent in place of sending it.
+// body is nil for requests without one.
+func PrintDryRun(method, path string, body interface{}, format Format) error {
+	if format == Text {
+		fmt.Fprintln(writer, "Dry run, nothing was changed. Request that would be sent:")
+		fmt.Fprintf(writer, "%s %s\n", method, path)
+		if body == nil {
+			return nil
+		}
+		data, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(writer, string(data))
+		return err
+	}
+
+	request := map[string]interface{}{
+		"method": method,
+		"path":   path,
+	}
+	if body != nil {
+		request["body"] = body
+	}
+	return Print(map[string]interface{}{
+		"status":  "dry_run",
+		"request": request,
+	}, Options{Format: format, Kind: KindDryRun})
+}
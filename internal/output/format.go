@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -24,34 +25,102 @@ func GetWriter() io.Writer {
 type Format string
 
 const (
-	JSON Format = "json"
-	Text Format = "text"
+	JSON   Format = "json"
+	Text   Format = "text"
+	YAML   Format = "yaml"
+	CSV    Format = "csv"
+	TSV    Format = "tsv"
+	NDJSON Format = "ndjson"
+
+	// Raw prints the API response as it was received, without the versioned envelope
+	Raw Format = "raw"
+
+	// Template formats carry their template after "=", as in jsonpath={.data[*].id}
+	GoTemplate Format = "go-template"
+	JSONPath   Format = "jsonpath"
 )
 
+// Formats lists the supported output formats, for flag usage and errors
+const Formats = "json, text, yaml, csv, tsv, ndjson, raw, go-template=TEMPLATE, jsonpath=TEMPLATE"
+
 func ParseFormat(format string) (Format, error) {
-	switch strings.ToLower(format) {
-	case "json":
-		return JSON, nil
-	case "text":
-		return Text, nil
+	name, template, hasTemplate := strings.Cut(format, "=")
+	switch f := Format(strings.ToLower(name)); f {
+	case JSON, Text, YAML, CSV, TSV, NDJSON, Raw:
+		if hasTemplate {
+			return "", fmt.Errorf("output format %s takes no template", f)
+		}
+		return f, nil
+	case GoTemplate, JSONPath:
+		if template == "" {
+			return "", fmt.Errorf("output format %s needs a template, as in %s=TEMPLATE", f, f)
+		}
+		// Catch template errors before any request is sent
+		if err := checkTemplate(f, template); err != nil {
+			return "", err
+		}
+		return f + "=" + Format(template), nil
 	default:
-		return "", fmt.Errorf("invalid output format: %s (supported: json, text)", format)
+		return "", fmt.Errorf("invalid output format: %s (supported: %s)", format, Formats)
 	}
 }
 
+// Name returns the format without its template
+func (f Format) Name() Format {
+	name, _, _ := strings.Cut(string(f), "=")
+	return Format(name)
+}
+
+// Template returns the template of a go-template or jsonpath format
+func (f Format) Template() string {
+	_, template, _ := strings.Cut(string(f), "=")
+	return template
+}
+
 // Add new type for column selection
 type Options struct {
 	Format  Format
 	Columns []string
+
+	// Kind selects the model JSON, YAML and NDJSON output is converted to
+	Kind string
+
+	// Filter and SortBy narrow and order the items of list responses, in every format
+	Filter    []Condition
+	SortBy    string
+	NoHeaders bool
 }
 
 // Update Print function signature
 func Print(data interface{}, opts Options) error {
-	switch opts.Format {
-	case JSON:
+	data = query(data, opts)
+
+	switch opts.Format.Name() {
+	case JSON, YAML, NDJSON:
+		model, err := envelope(data, opts.Kind)
+		if err != nil {
+			return err
+		}
+		switch opts.Format {
+		case JSON:
+			return printJSON(model)
+		case YAML:
+			return printYAML(model)
+		default:
+			return printNDJSON(model)
+		}
+	case Raw:
 		return printJSON(data)
 	case Text:
-		return printText(data, opts.Columns)
+		return printText(data, opts)
+	case CSV:
+		return printDelimited(data, opts, ',')
+	case TSV:
+		return printDelimited(data, opts, '\t')
+	case GoTemplate:
+		return printGoTemplate(data, opts.Format.Template())
+	case JSONPath:
+		return printJSONPath(data, opts.Format.Template())
 	default:
 		return fmt.Errorf("unsupported output format: %s", opts.Format)
 	}
@@ -66,7 +135,7 @@ func printJSON(data interface{}) error {
 	return err
 }
 
-func printText(data interface{}, columns []string) error {
+func printText(data interface{}, opts Options) error {
 	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
 	defer w.Flush()
 
@@ -79,23 +148,25 @@ func printText(data interface{}, columns []string) error {
 
 	switch v := data.(type) {
 	case map[string]interface{}:
-		return printSingleTable(w, v, columns)
+		return printSingleTable(w, v, opts.NoHeaders)
 	case []interface{}:
 		if len(v) == 0 {
 			fmt.Fprintln(w, "No data available")
 			return nil
 		}
-		return printArrayTable(w, v, columns)
+		return printArrayTable(w, v, opts)
 	default:
 		fmt.Fprintf(w, "%v\n", v)
 	}
 	return nil
 }
 
-func printSingleTable(w *tabwriter.Writer, data map[string]interface{}, columns []string) error {
+func printSingleTable(w *tabwriter.Writer, data map[string]interface{}, noHeaders bool) error {
 	// Print header
-	fmt.Fprintln(w, "KEY\tVALUE")
-	fmt.Fprintln(w, "---\t-----")
+	if !noHeaders {
+		fmt.Fprintln(w, "KEY\tVALUE")
+		fmt.Fprintln(w, "---\t-----")
+	}
 
 	// Sort keys for consistent output
 	keys := make([]string, 0, len(data))
@@ -120,7 +191,7 @@ func printSingleTable(w *tabwriter.Writer, data map[string]interface{}, columns
 }
 
 // Update printArrayTable to use columns
-func printArrayTable(w *tabwriter.Writer, data []interface{}, columns []string) error {
+func printArrayTable(w *tabwriter.Writer, data []interface{}, opts Options) error {
 	if len(data) == 0 {
 		return nil
 	}
@@ -132,37 +203,26 @@ func printArrayTable(w *tabwriter.Writer, data []interface{}, columns []string)
 
 	// Check if this is a config list or mapping list
 	if _, hasConfig := firstItem["config"]; hasConfig {
-		return printMappingTable(w, data, columns)
+		return printTable(w, data, selectColumns(mappingColumns, opts.Columns), mappingValue, opts.NoHeaders)
 	}
-	return printConfigTable(w, data, columns)
+	return printTable(w, data, selectColumns(configColumns, opts.Columns), configValue, opts.NoHeaders)
 }
 
-// Update printMappingTable to use columns
-func printMappingTable(w *tabwriter.Writer, data []interface{}, requestedColumns []string) error {
-	// Default columns if none specified
-	defaultColumns := []string{
-		"id",
-		"region",
-		"config_id",
-		"config_name",
-		"config_type",
-		"hostname",
-		"protocol",
-		"port_from",
-		"port_to",
-		"created_at",
-		"active",
-	}
+// mappingColumns and configColumns are the columns of the tables when none are requested
+var (
+	mappingColumns = defaultNames(MappingColumns)
+	configColumns  = defaultNames(ConfigColumns)
+)
 
-	// Use requested columns if provided, otherwise use defaults
-	columnOrder := defaultColumns
-	if len(requestedColumns) > 0 {
-		columnOrder = requestedColumns
-	}
+// valueFunc formats one column of a row
+type valueFunc func(row map[string]interface{}, column string) string
 
+func printTable(w *tabwriter.Writer, data []interface{}, columns []string, value valueFunc, noHeaders bool) error {
 	// Print headers
-	fmt.Fprintln(w, strings.Join(columnOrder, "\t"))
-	fmt.Fprintln(w, strings.Repeat("---\t", len(columnOrder)))
+	if !noHeaders {
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
+		fmt.Fprintln(w, strings.Repeat("---\t", len(columns)))
+	}
 
 	// Print rows
 	for _, item := range data {
@@ -171,28 +231,9 @@ func printMappingTable(w *tabwriter.Writer, data []interface{}, requestedColumns
 			continue
 		}
 
-		values := make([]string, len(columnOrder))
-		for i, header := range columnOrder {
-			switch header {
-			case "config_name", "config_type", "region", "config_id": // Add config_id here
-				if config, ok := row["config"].(map[string]interface{}); ok {
-					name, typ, reg, id := extractConfigInfo(config)
-					switch header {
-					case "config_name":
-						values[i] = name
-					case "config_type":
-						values[i] = typ
-					case "region":
-						values[i] = reg
-					case "config_id":
-						values[i] = id
-					}
-				} else {
-					values[i] = "-"
-				}
-			default:
-				values[i] = formatValue(row[header])
-			}
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			values[i] = value(row, column)
 		}
 		fmt.Fprintln(w, strings.Join(values, "\t"))
 	}
@@ -200,64 +241,39 @@ func printMappingTable(w *tabwriter.Writer, data []interface{}, requestedColumns
 	return nil
 }
 
-func printConfigTable(w *tabwriter.Writer, data []interface{}, requestedColumns []string) error {
-	// Default columns if none specified
-	defaultColumns := []string{
-		"id",
-		"region",
-		"name",
-		"type",
-		"proto",
-		"created_at",
-		"comment",
-	}
-
-	// Use requested columns if provided, otherwise use defaults
-	columnOrder := defaultColumns
-	if len(requestedColumns) > 0 {
-		columnOrder = requestedColumns
+// selectColumns returns the requested columns, or the defaults when none are requested
+func selectColumns(defaults, requested []string) []string {
+	if len(requested) > 0 {
+		return requested
 	}
+	return defaults
+}
 
-	// Print headers
-	fmt.Fprintln(w, strings.Join(columnOrder, "\t"))
-	fmt.Fprintln(w, strings.Repeat("---\t", len(columnOrder)))
-
-	// Print rows
-	for _, item := range data {
-		row, ok := item.(map[string]interface{})
+// mappingValue formats a column of a mapping, taking the config columns from its nested config
+func mappingValue(row map[string]interface{}, column string) string {
+	switch column {
+	case "config_name", "config_type", "region", "config_id":
+		config, ok := row["config"].(map[string]interface{})
 		if !ok {
-			continue
+			return "-"
 		}
-
-		values := make([]string, len(columnOrder))
-		for i, header := range columnOrder {
-			switch header {
-			case "name", "type", "region", "proto", "comment":
-				if val, exists := row[header]; exists {
-					values[i] = formatValue(val)
-				} else {
-					values[i] = "-"
-				}
-			case "created_at":
-				if val, exists := row[header]; exists {
-					values[i] = formatValue(val)
-				} else {
-					values[i] = "-"
-				}
-			case "id":
-				if val, exists := row[header]; exists {
-					values[i] = formatValue(val)
-				} else {
-					values[i] = "-"
-				}
-			default:
-				values[i] = formatValue(row[header])
-			}
+		name, typ, reg, id := extractConfigInfo(config)
+		switch column {
+		case "config_name":
+			return name
+		case "config_type":
+			return typ
+		case "region":
+			return reg
+		default:
+			return id
 		}
-		fmt.Fprintln(w, strings.Join(values, "\t"))
 	}
+	return formatValue(row[column])
+}
 
-	return nil
+func configValue(row map[string]interface{}, column string) string {
+	return formatValue(row[column])
 }
 
 func extractConfigInfo(config map[string]interface{}) (name, configType, region, id string) {
@@ -308,6 +324,13 @@ func formatValue(v interface{}) string {
 	case nil:
 		return "-"
 	default:
+		// Typed results, such as a slice of structs, read better as JSON
+		switch reflect.ValueOf(val).Kind() {
+		case reflect.Struct, reflect.Slice, reflect.Map:
+			if b, err := json.Marshal(val); err == nil {
+				return string(b)
+			}
+		}
 		return fmt.Sprintf("%v", val)
 	}
 }
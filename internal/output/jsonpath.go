@@ -0,0 +1,548 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed kubectl style JSONPath template: text with {expression}
+// blocks, {range expression}...{end} loops and {"literal"} strings. Expressions
+// support .field, ['field'], ..field, [n], [start:end], [*] and [?(@.field op value)].
+type jsonPath struct {
+	nodes []pathNode
+}
+
+// pathNode is literal text, or an expression when isExpr is set
+type pathNode struct {
+	text   string
+	isExpr bool
+	path   []pathStep
+	root   bool
+	loop   bool
+	body   []pathNode
+}
+
+type stepKind int
+
+const (
+	stepField stepKind = iota
+	stepRecursive
+	stepWildcard
+	stepIndex
+	stepSlice
+	stepFilter
+)
+
+type pathStep struct {
+	kind       stepKind
+	name       string
+	index      int
+	start, end *int
+	filter     *pathFilter
+}
+
+type pathFilter struct {
+	path  []pathStep
+	op    string // empty when only checking that path exists
+	value interface{}
+}
+
+func parseJSONPath(template string) (*jsonPath, error) {
+	if !strings.Contains(template, "{") {
+		template = "{" + template + "}"
+	}
+
+	var stack [][]pathNode
+	var current []pathNode
+	for len(template) > 0 {
+		open := strings.Index(template, "{")
+		if open < 0 {
+			current = append(current, pathNode{text: template})
+			break
+		}
+		if open > 0 {
+			current = append(current, pathNode{text: template[:open]})
+		}
+		end := closingBrace(template, open)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed { in jsonpath template")
+		}
+		expr := strings.TrimSpace(template[open+1 : end])
+		template = template[end+1:]
+
+		switch {
+		case expr == "end":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("{end} without {range} in jsonpath template")
+			}
+			loop := stack[len(stack)-1]
+			loop[len(loop)-1].body = current
+			current, stack = loop, stack[:len(stack)-1]
+		case strings.HasPrefix(expr, "range "):
+			node, err := parsePathNode(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			node.loop = true
+			stack = append(stack, append(current, node))
+			current = nil
+		case strings.HasPrefix(expr, `"`):
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s in jsonpath template", expr)
+			}
+			current = append(current, pathNode{text: text})
+		default:
+			node, err := parsePathNode(expr)
+			if err != nil {
+				return nil, err
+			}
+			current = append(current, node)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("{range} without {end} in jsonpath template")
+	}
+	return &jsonPath{nodes: current}, nil
+}
+
+// closingBrace returns the index of the } closing the { at open, skipping quoted text
+func closingBrace(s string, open int) int {
+	var quote byte
+	for i := open + 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func parsePathNode(expr string) (pathNode, error) {
+	node := pathNode{isExpr: true}
+	switch {
+	case strings.HasPrefix(expr, "$"):
+		node.root = true
+		expr = expr[1:]
+	case strings.HasPrefix(expr, "@"):
+		expr = expr[1:]
+	}
+	steps, err := parseSteps(expr)
+	if err != nil {
+		return node, fmt.Errorf("invalid jsonpath expression %q: %w", expr, err)
+	}
+	node.path = steps
+	return node, nil
+}
+
+func parseSteps(expr string) ([]pathStep, error) {
+	var steps []pathStep
+	for i := 0; i < len(expr); {
+		switch {
+		case strings.HasPrefix(expr[i:], ".."):
+			name, n := readName(expr[i+2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing field name after ..")
+			}
+			steps = append(steps, pathStep{kind: stepRecursive, name: name})
+			i += 2 + n
+		case expr[i] == '.':
+			name, n := readName(expr[i+1:])
+			switch name {
+			case "":
+			case "*":
+				steps = append(steps, pathStep{kind: stepWildcard})
+			default:
+				steps = append(steps, pathStep{kind: stepField, name: name})
+			}
+			i += 1 + n
+		case expr[i] == '[':
+			end := closingBracket(expr, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [")
+			}
+			step, err := parseBracket(strings.TrimSpace(expr[i+1 : end]))
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			i = end + 1
+		default:
+			name, n := readName(expr[i:])
+			if i > 0 || name == "" {
+				return nil, fmt.Errorf("unexpected %q", expr[i:])
+			}
+			// A leading field without a dot, as in {data[0].id}
+			steps = append(steps, pathStep{kind: stepField, name: name})
+			i += n
+		}
+	}
+	return steps, nil
+}
+
+func readName(s string) (string, int) {
+	n := strings.IndexAny(s, ".[")
+	if n < 0 {
+		n = len(s)
+	}
+	return s[:n], n
+}
+
+// closingBracket returns the index of the ] closing the [ at open, skipping quoted text
+func closingBracket(s string, open int) int {
+	var quote byte
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch {
+		case quote != 0:
+			if s[i] == quote {
+				quote = 0
+			}
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '[':
+			depth++
+		case s[i] == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func parseBracket(content string) (pathStep, error) {
+	switch {
+	case content == "*":
+		return pathStep{kind: stepWildcard}, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		filter, err := parseFilter(strings.TrimSpace(content[2 : len(content)-1]))
+		if err != nil {
+			return pathStep{}, err
+		}
+		return pathStep{kind: stepFilter, filter: filter}, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return pathStep{kind: stepField, name: content[1 : len(content)-1]}, nil
+	case strings.Contains(content, ":"):
+		parts := strings.SplitN(content, ":", 2)
+		step := pathStep{kind: stepSlice}
+		for i, part := range parts {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return pathStep{}, fmt.Errorf("invalid slice [%s]", content)
+			}
+			if i == 0 {
+				step.start = &n
+			} else {
+				step.end = &n
+			}
+		}
+		return step, nil
+	default:
+		n, err := strconv.Atoi(content)
+		if err != nil {
+			return pathStep{}, fmt.Errorf("invalid index [%s]", content)
+		}
+		return pathStep{kind: stepIndex, index: n}, nil
+	}
+}
+
+func parseFilter(expr string) (*pathFilter, error) {
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("filter %q must start with @", expr)
+	}
+
+	// Find the operator outside of quoted text, two character operators first
+	var quote byte
+	for i := 1; i < len(expr); i++ {
+		if quote != 0 {
+			if expr[i] == quote {
+				quote = 0
+			}
+			continue
+		}
+		if expr[i] == '"' || expr[i] == '\'' {
+			quote = expr[i]
+			continue
+		}
+		for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+			if !strings.HasPrefix(expr[i:], op) {
+				continue
+			}
+			path, err := parseSteps(strings.TrimSpace(expr[1:i]))
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseLiteral(strings.TrimSpace(expr[i+len(op):]))
+			if err != nil {
+				return nil, err
+			}
+			return &pathFilter{path: path, op: op, value: value}, nil
+		}
+	}
+
+	path, err := parseSteps(expr[1:])
+	if err != nil {
+		return nil, err
+	}
+	return &pathFilter{path: path}, nil
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], nil
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q in filter", s)
+	}
+	return n, nil
+}
+
+// execute writes the template for data, separating the values of an expression with spaces
+func (p *jsonPath) execute(w io.Writer, data interface{}) error {
+	return executeNodes(w, p.nodes, data, data)
+}
+
+func executeNodes(w io.Writer, nodes []pathNode, root, current interface{}) error {
+	for _, node := range nodes {
+		if !node.isExpr {
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return err
+			}
+			continue
+		}
+
+		start := current
+		if node.root {
+			start = root
+		}
+		values, err := evaluate(node.path, []interface{}{start})
+		if err != nil {
+			return err
+		}
+
+		if node.loop {
+			for _, v := range values {
+				if err := executeNodes(w, node.body, root, v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		texts := make([]string, len(values))
+		for i, v := range values {
+			texts[i] = pathText(v)
+		}
+		if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func evaluate(steps []pathStep, values []interface{}) ([]interface{}, error) {
+	for _, step := range steps {
+		var next []interface{}
+		for _, v := range values {
+			switch step.kind {
+			case stepField:
+				// Missing fields are skipped, as with kubectl get
+				if m, ok := v.(map[string]interface{}); ok {
+					if field, exists := m[step.name]; exists {
+						next = append(next, field)
+					}
+				}
+			case stepRecursive:
+				next = append(next, descendants(v, step.name)...)
+			case stepWildcard:
+				next = append(next, children(v)...)
+			case stepIndex:
+				list, ok := v.([]interface{})
+				if !ok {
+					continue
+				}
+				i := step.index
+				if i < 0 {
+					i += len(list)
+				}
+				if i < 0 || i >= len(list) {
+					return nil, fmt.Errorf("array index %d is out of bounds", step.index)
+				}
+				next = append(next, list[i])
+			case stepSlice:
+				if list, ok := v.([]interface{}); ok {
+					next = append(next, slice(list, step.start, step.end)...)
+				}
+			case stepFilter:
+				for _, item := range children(v) {
+					matched, err := step.filter.match(item)
+					if err != nil {
+						return nil, err
+					}
+					if matched {
+						next = append(next, item)
+					}
+				}
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// children returns the items of a list or the values of an object, ordered by key
+func children(v interface{}) []interface{} {
+	switch val := v.(type) {
+	case []interface{}:
+		return val
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, len(keys))
+		for i, k := range keys {
+			values[i] = val[k]
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns the name fields of v and everything nested in it, or every
+// nested value for *
+func descendants(v interface{}, name string) []interface{} {
+	var found []interface{}
+	if m, ok := v.(map[string]interface{}); ok && name != "*" {
+		if field, exists := m[name]; exists {
+			found = append(found, field)
+		}
+	}
+	for _, child := range children(v) {
+		if name == "*" {
+			found = append(found, child)
+		}
+		found = append(found, descendants(child, name)...)
+	}
+	return found
+}
+
+func slice(list []interface{}, start, end *int) []interface{} {
+	from, to := 0, len(list)
+	if start != nil {
+		from = *start
+	}
+	if end != nil {
+		to = *end
+	}
+	if from < 0 {
+		from += len(list)
+	}
+	if to < 0 {
+		to += len(list)
+	}
+	from = max(0, min(from, len(list)))
+	to = max(from, min(to, len(list)))
+	return list[from:to]
+}
+
+func (f *pathFilter) match(item interface{}) (bool, error) {
+	values, err := evaluate(f.path, []interface{}{item})
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	if f.op == "" {
+		return true, nil
+	}
+	v := values[0]
+
+	switch f.op {
+	case "==":
+		return equal(v, f.value), nil
+	case "!=":
+		return !equal(v, f.value), nil
+	}
+
+	a, aok := number(v)
+	b, bok := number(f.value)
+	if !aok || !bok {
+		return false, fmt.Errorf("%s compares numbers only", f.op)
+	}
+	switch f.op {
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	default:
+		return a >= b, nil
+	}
+}
+
+// equal compares a value from the data with a filter literal. Numbers the API
+// sends as strings, such as ports, equal the same number.
+func equal(v, literal interface{}) bool {
+	if a, ok := number(v); ok {
+		if b, ok := number(literal); ok {
+			return a == b
+		}
+	}
+	return fmt.Sprint(v) == fmt.Sprint(literal)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case json.Number:
+		n, err := val.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(val, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// pathText formats a value the way kubectl does: strings bare, everything else as JSON
+func pathText(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case json.Number:
+		return val.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,427 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIVersion identifies the schema of JSON, YAML and NDJSON output. Fields are
+// only ever added within a version; renaming or removing one means a new version.
+const APIVersion = "portmap/v1"
+
+// Kinds of output, one per model below. Commands pass theirs in Options.Kind.
+const (
+	KindMapping             = "Mapping"
+	KindMappingList         = "MappingList"
+	KindConfig              = "Config"
+	KindConfigList          = "ConfigList"
+	KindRegionList          = "RegionList"
+	KindProbeResultList     = "ProbeResultList"
+	KindMappingDeletionList = "MappingDeletionList"
+	KindStatus              = "Status"
+	KindKeyRotation         = "KeyRotation"
+	KindDryRun              = "DryRun"
+	KindPlan                = "Plan"
+	KindWatchEvent          = "WatchEvent"
+)
+
+// TypeMeta starts every object of the versioned output
+type TypeMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+func typeMeta(kind string) TypeMeta {
+	return TypeMeta{APIVersion: APIVersion, Kind: kind}
+}
+
+// Mapping is a mapping rule
+type Mapping struct {
+	TypeMeta
+	ID              int64  `json:"id"`
+	Hostname        string `json:"hostname"`
+	Protocol        string `json:"protocol"`
+	PortFrom        int64  `json:"port_from"`
+	PortTo          int64  `json:"port_to"`
+	ConfigID        int64  `json:"config_id"`
+	ConfigName      string `json:"config_name,omitempty"`
+	ConfigType      string `json:"config_type,omitempty"`
+	Region          string `json:"region"`
+	HostHeader      string `json:"hostheader,omitempty"`
+	AllowedIP       string `json:"allowed_ip,omitempty"`
+	UseCustomDomain bool   `json:"use_custom_domain"`
+	WebSockets      bool   `json:"websockets"`
+	WSTimeout       int64  `json:"ws_timeout,omitempty"`
+	ProxyToHTTP     bool   `json:"proxy_to_http"`
+	Active          bool   `json:"active"`
+	CreatedAt       string `json:"created_at,omitempty"`
+}
+
+// MappingList is the output of mapping list
+type MappingList struct {
+	TypeMeta
+	Items []Mapping `json:"items"`
+}
+
+// Config is a configuration. ConfigFile is only set by commands that fetch it,
+// File only by commands that saved it.
+type Config struct {
+	TypeMeta
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Region       string `json:"region"`
+	OpenVPNProto string `json:"openvpn_proto,omitempty"`
+	Comment      string `json:"comment,omitempty"`
+	CreatedAt    string `json:"created_at,omitempty"`
+	ConfigFile   string `json:"config_file,omitempty"`
+	File         string `json:"file,omitempty"`
+}
+
+// ConfigList is the output of config list
+type ConfigList struct {
+	TypeMeta
+	Items []Config `json:"items"`
+}
+
+// Region is a region of the catalog
+type Region struct {
+	TypeMeta
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Label    string `json:"label,omitempty"`
+}
+
+// RegionList is the output of regions list
+type RegionList struct {
+	TypeMeta
+	Items []Region `json:"items"`
+}
+
+// ProbeResult is the round trip time to a region, or why it couldn't be measured
+type ProbeResult struct {
+	TypeMeta
+	Region   string   `json:"region"`
+	Hostname string   `json:"hostname"`
+	RTTMs    *float64 `json:"rtt_ms,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// ProbeResultList is the output of regions probe, fastest first
+type ProbeResultList struct {
+	TypeMeta
+	Items []ProbeResult `json:"items"`
+}
+
+// MappingDeletion is the outcome of deleting one mapping of a bulk delete
+type MappingDeletion struct {
+	TypeMeta
+	ID      int64  `json:"id"`
+	Mapping string `json:"mapping,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// MappingDeletionList is the output of a bulk mapping delete
+type MappingDeletionList struct {
+	TypeMeta
+	Items []MappingDeletion `json:"items"`
+}
+
+// Status is the output of commands that only report success, such as delete
+type Status struct {
+	TypeMeta
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// KeyRotation is the output of config rotate-key
+type KeyRotation struct {
+	TypeMeta
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	File      string `json:"file"`
+	PublicKey string `json:"public_key"`
+	Restarted bool   `json:"restarted"`
+}
+
+// DryRun is the request a command would have sent without --dry-run
+type DryRun struct {
+	TypeMeta
+	Request DryRunRequest `json:"request"`
+}
+
+// DryRunRequest is an API request
+type DryRunRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Body   interface{} `json:"body,omitempty"`
+}
+
+// Plan is the output of apply and import: the changes planned, or carried out
+// unless DryRun is set
+type Plan struct {
+	TypeMeta
+	DryRun    bool              `json:"dry_run"`
+	Message   string            `json:"message,omitempty"`
+	Actions   []PlanAction      `json:"actions"`
+	Skipped   []string          `json:"skipped,omitempty"`
+	ConfigIDs map[string]string `json:"config_ids,omitempty"`
+}
+
+// PlanAction is one change of a plan. Mapping is empty for config changes.
+type PlanAction struct {
+	Op       string   `json:"op"`
+	Config   string   `json:"config"`
+	ConfigID string   `json:"config_id,omitempty"`
+	Mapping  string   `json:"mapping,omitempty"`
+	Changes  []string `json:"changes,omitempty"`
+}
+
+// WatchEvent is a change seen by --watch: ADDED, MODIFIED or DELETED. Object is
+// the model of the items of the watched kind, such as a Mapping for a MappingList.
+type WatchEvent struct {
+	TypeMeta
+	Type   string      `json:"type"`
+	Time   time.Time   `json:"time"`
+	Object interface{} `json:"object"`
+}
+
+// envelope converts a command's data into the model of kind. Data without a
+// kind is returned as it is.
+func envelope(data interface{}, kind string) (interface{}, error) {
+	if kind == "" {
+		return data, nil
+	}
+	// Typed values, such as a slice of structs under "data", are read as decoded JSON
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	body := unwrap(data)
+
+	switch kind {
+	case KindMapping:
+		return toMapping(object(body)), nil
+	case KindMappingList:
+		list := MappingList{TypeMeta: typeMeta(kind), Items: []Mapping{}}
+		for _, item := range objects(body) {
+			list.Items = append(list.Items, toMapping(item))
+		}
+		return list, nil
+	case KindConfig:
+		config := toConfig(object(body))
+		config.File = text(object(data)["file"])
+		return config, nil
+	case KindConfigList:
+		list := ConfigList{TypeMeta: typeMeta(kind), Items: []Config{}}
+		for _, item := range objects(body) {
+			list.Items = append(list.Items, toConfig(item))
+		}
+		return list, nil
+	case KindRegionList:
+		list := RegionList{TypeMeta: typeMeta(kind), Items: []Region{}}
+		for _, item := range objects(body) {
+			list.Items = append(list.Items, Region{
+				TypeMeta: typeMeta("Region"),
+				Name:     text(item["name"]),
+				Hostname: text(item["hostname"]),
+				Label:    text(item["label"]),
+			})
+		}
+		return list, nil
+	case KindProbeResultList:
+		list := ProbeResultList{TypeMeta: typeMeta(kind), Items: []ProbeResult{}}
+		for _, item := range objects(body) {
+			result := ProbeResult{
+				TypeMeta: typeMeta("ProbeResult"),
+				Region:   text(item["region"]),
+				Hostname: text(item["hostname"]),
+				Error:    text(item["error"]),
+			}
+			if rtt, ok := item["rtt_ms"].(float64); ok {
+				result.RTTMs = &rtt
+			}
+			list.Items = append(list.Items, result)
+		}
+		return list, nil
+	case KindMappingDeletionList:
+		list := MappingDeletionList{TypeMeta: typeMeta(kind), Items: []MappingDeletion{}}
+		for _, item := range objects(object(data)["results"]) {
+			list.Items = append(list.Items, MappingDeletion{
+				TypeMeta: typeMeta("MappingDeletion"),
+				ID:       integer(item["id"]),
+				Mapping:  text(item["mapping"]),
+				Status:   text(item["status"]),
+				Error:    text(item["error"]),
+			})
+		}
+		return list, nil
+	case KindStatus:
+		m := object(data)
+		return Status{TypeMeta: typeMeta(kind), Status: text(m["status"]), Message: text(m["message"])}, nil
+	case KindKeyRotation:
+		m := object(data)
+		return KeyRotation{
+			TypeMeta:  typeMeta(kind),
+			Status:    text(m["status"]),
+			Message:   text(m["message"]),
+			File:      text(m["file"]),
+			PublicKey: text(m["public_key"]),
+			Restarted: m["restarted"] == true,
+		}, nil
+	case KindDryRun:
+		request := object(object(data)["request"])
+		return DryRun{TypeMeta: typeMeta(kind), Request: DryRunRequest{
+			Method: text(request["method"]),
+			Path:   text(request["path"]),
+			Body:   request["body"],
+		}}, nil
+	case KindPlan:
+		return toPlan(object(data)), nil
+	default:
+		return nil, fmt.Errorf("unknown output kind: %s", kind)
+	}
+}
+
+func toMapping(m map[string]interface{}) Mapping {
+	mapping := Mapping{
+		TypeMeta:        typeMeta(KindMapping),
+		ID:              integer(m["id"]),
+		Hostname:        text(m["hostname"]),
+		Protocol:        text(m["protocol"]),
+		PortFrom:        integer(m["port_from"]),
+		PortTo:          integer(m["port_to"]),
+		ConfigID:        integer(m["config_id"]),
+		Region:          text(m["region"]),
+		HostHeader:      text(m["hostheader"]),
+		AllowedIP:       text(m["allowed_ip"]),
+		UseCustomDomain: m["use_custom_domain"] == true,
+		WebSockets:      m["websockets"] == true,
+		WSTimeout:       integer(m["ws_timeout"]),
+		ProxyToHTTP:     m["proxy_to_http"] == true,
+		Active:          m["active"] == true,
+		CreatedAt:       text(m["created_at"]),
+	}
+	if config, ok := m["config"].(map[string]interface{}); ok {
+		if id := integer(config["id"]); id != 0 {
+			mapping.ConfigID = id
+		}
+		mapping.ConfigName = text(config["name"])
+		mapping.ConfigType = text(config["type"])
+		if region := text(config["region"]); region != "" {
+			mapping.Region = region
+		}
+	}
+	if mapping.Region == "" {
+		mapping.Region = "default"
+	}
+	return mapping
+}
+
+func toConfig(m map[string]interface{}) Config {
+	config := Config{
+		TypeMeta:     typeMeta(KindConfig),
+		ID:           integer(m["id"]),
+		Name:         text(m["name"]),
+		Type:         text(m["type"]),
+		Region:       text(m["region"]),
+		OpenVPNProto: text(m["openvpn_proto"]),
+		Comment:      text(m["comment"]),
+		CreatedAt:    text(m["created_at"]),
+		ConfigFile:   text(m["config_file"]),
+	}
+	if config.OpenVPNProto == "" && config.Type == "OpenVPN" {
+		config.OpenVPNProto = text(m["proto"])
+	}
+	if config.Region == "" {
+		config.Region = "default"
+	}
+	return config
+}
+
+func toPlan(m map[string]interface{}) Plan {
+	plan := Plan{
+		TypeMeta: typeMeta(KindPlan),
+		DryRun:   m["dry_run"] == true,
+		Message:  text(m["message"]),
+		Actions:  []PlanAction{},
+	}
+	for _, a := range objects(m["actions"]) {
+		config := object(a["config"])
+		action := PlanAction{
+			Op:       text(a["op"]),
+			Config:   text(config["name"]),
+			ConfigID: text(config["id"]),
+		}
+		if mapping, ok := a["mapping"].(map[string]interface{}); ok {
+			action.Mapping = fmt.Sprintf("%s://%s:%s", text(mapping["protocol"]), text(mapping["hostname"]), text(mapping["port_from"]))
+		}
+		for _, change := range list(a["changes"]) {
+			action.Changes = append(action.Changes, text(change))
+		}
+		plan.Actions = append(plan.Actions, action)
+	}
+	for _, note := range list(m["skipped"]) {
+		plan.Skipped = append(plan.Skipped, text(note))
+	}
+	if ids := object(m["config_ids"]); len(ids) > 0 {
+		plan.ConfigIDs = make(map[string]string, len(ids))
+		for k, v := range ids {
+			plan.ConfigIDs[k] = text(v)
+		}
+	}
+	return plan
+}
+
+func object(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
+
+func objects(v interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+	for _, item := range list(v) {
+		if m, ok := item.(map[string]interface{}); ok {
+			items = append(items, m)
+		}
+	}
+	return items
+}
+
+// text formats a value of an API response, with numbers free of exponents and nulls empty
+func text(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// integer reads a number the API may send as a JSON number or a string
+func integer(v interface{}) int64 {
+	switch val := v.(type) {
+	case float64:
+		return int64(val)
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		return n
+	}
+	return 0
+}
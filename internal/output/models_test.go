@@ -0,0 +1,54 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintVersionedList(t *testing.T) {
+	out := decode(t, capture(t, decode(t, mappingList), Options{Format: JSON, Kind: KindMappingList}))
+	list := out.(map[string]interface{})
+	assert.Equal(t, APIVersion, list["apiVersion"])
+	assert.Equal(t, KindMappingList, list["kind"])
+	assert.NotContains(t, list, "data")
+
+	items := list["items"].([]interface{})
+	require.Len(t, items, 2)
+	first := items[0].(map[string]interface{})
+	assert.Equal(t, KindMapping, first["kind"])
+	assert.Equal(t, float64(8080), first["port_to"])
+	assert.Equal(t, float64(3), first["config_id"])
+	assert.Equal(t, "office", first["config_name"])
+	assert.Equal(t, "fra1", first["region"])
+}
+
+func TestPrintVersionedNDJSON(t *testing.T) {
+	out := capture(t, decode(t, mappingList), Options{Format: NDJSON, Kind: KindMappingList})
+	assert.Contains(t, out, `{"apiVersion":"portmap/v1","kind":"Mapping","id":12345678,`)
+}
+
+func TestPrintRaw(t *testing.T) {
+	out := decode(t, capture(t, decode(t, mappingList), Options{Format: Raw, Kind: KindMappingList}))
+	assert.Contains(t, out, "data")
+	assert.NotContains(t, out, "apiVersion")
+}
+
+func TestEnvelopeUnknownKind(t *testing.T) {
+	_, err := envelope(map[string]interface{}{}, "Widget")
+	assert.EqualError(t, err, "unknown output kind: Widget")
+}
+
+func TestEnvelopeTypedItems(t *testing.T) {
+	type result struct {
+		ID     int64  `json:"id"`
+		Status string `json:"status"`
+	}
+	out, err := envelope(map[string]interface{}{"status": "success", "results": []result{{7, "deleted"}}}, KindMappingDeletionList)
+	require.NoError(t, err)
+	list := out.(MappingDeletionList)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, int64(7), list.Items[0].ID)
+	assert.Equal(t, "deleted", list.Items[0].Status)
+}
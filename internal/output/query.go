@@ -0,0 +1,178 @@
+package output
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Condition is one comparison of a --filter, as in port_from>=8000
+type Condition struct {
+	Column string
+	Op     string
+	Value  string
+}
+
+// filterOps are the operators of a condition, longest first so >= isn't read as >
+var filterOps = []string{"!=", ">=", "<=", "==", "=", ">", "<"}
+
+// ParseFilter parses comma separated conditions such as
+// "port_from>=8000,hostname=*.dev.portmap.io". = and != match shell patterns,
+// the other operators compare numbers, or text when a side isn't a number.
+func ParseFilter(expr string, available []Column) ([]Condition, error) {
+	var conditions []Condition
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var c Condition
+		for i := range part {
+			for _, op := range filterOps {
+				if strings.HasPrefix(part[i:], op) {
+					c = Condition{
+						Column: strings.TrimSpace(part[:i]),
+						Op:     op,
+						Value:  strings.TrimSpace(part[i+len(op):]),
+					}
+					if op == "==" {
+						c.Op = "="
+					}
+					break
+				}
+			}
+			if c.Op != "" {
+				break
+			}
+		}
+		if c.Op == "" {
+			return nil, fmt.Errorf("invalid filter %q: expected column, operator (=, !=, <, <=, >, >=) and value", part)
+		}
+		if err := CheckColumns(available, []string{c.Column}); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", part, err)
+		}
+		if _, err := path.Match(c.Value, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter %q: bad pattern", part)
+		}
+		conditions = append(conditions, c)
+	}
+	return conditions, nil
+}
+
+// CheckSort validates a --sort-by column, which a leading - sorts in descending order
+func CheckSort(sortBy string, available []Column) error {
+	if sortBy == "" {
+		return nil
+	}
+	return CheckColumns(available, []string{strings.TrimPrefix(sortBy, "-")})
+}
+
+func (c Condition) match(cell string) bool {
+	switch c.Op {
+	case "=":
+		matched, _ := path.Match(c.Value, cell)
+		return matched
+	case "!=":
+		matched, _ := path.Match(c.Value, cell)
+		return !matched
+	}
+
+	// "-" stands for a missing value, which no comparison holds for
+	if cell == "-" {
+		return false
+	}
+	cmp := compareCells(cell, c.Value)
+	switch c.Op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// compareCells compares two formatted values, as numbers when both are
+func compareCells(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// query filters and sorts the items of a list response, keeping the rest of the
+// response as it is. Other responses are returned unchanged.
+func query(data interface{}, opts Options) interface{} {
+	if len(opts.Filter) == 0 && opts.SortBy == "" {
+		return data
+	}
+	items, ok := unwrap(data).([]interface{})
+	if !ok || len(items) == 0 {
+		return data
+	}
+
+	value := configValue
+	if first, ok := items[0].(map[string]interface{}); ok {
+		if _, hasConfig := first["config"]; hasConfig {
+			value = mappingValue
+		}
+	}
+	cell := func(item interface{}, column string) string {
+		row, _ := item.(map[string]interface{})
+		return value(row, column)
+	}
+
+	selected := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		matched := true
+		for _, c := range opts.Filter {
+			if !c.match(cell(item, c.Column)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			selected = append(selected, item)
+		}
+	}
+
+	if opts.SortBy != "" {
+		column := strings.TrimPrefix(opts.SortBy, "-")
+		descending := column != opts.SortBy
+		sort.SliceStable(selected, func(i, j int) bool {
+			a, b := cell(selected[i], column), cell(selected[j], column)
+			// Missing values go last either way
+			if a == "-" || b == "-" {
+				return b == "-" && a != "-"
+			}
+			if descending {
+				return compareCells(a, b) > 0
+			}
+			return compareCells(a, b) < 0
+		})
+	}
+
+	wrapper, ok := data.(map[string]interface{})
+	if !ok {
+		return selected
+	}
+	result := make(map[string]interface{}, len(wrapper))
+	for k, v := range wrapper {
+		result[k] = v
+	}
+	result["data"] = selected
+	return result
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	conditions, err := ParseFilter("port_from>=8000, hostname==*.dev.portmap.io,protocol!=udp", MappingColumns)
+	require.NoError(t, err)
+	assert.Equal(t, []Condition{
+		{Column: "port_from", Op: ">=", Value: "8000"},
+		{Column: "hostname", Op: "=", Value: "*.dev.portmap.io"},
+		{Column: "protocol", Op: "!=", Value: "udp"},
+	}, conditions)
+
+	_, err = ParseFilter("port_from", MappingColumns)
+	assert.ErrorContains(t, err, "expected column, operator")
+	_, err = ParseFilter("name=office", MappingColumns)
+	assert.ErrorContains(t, err, "unknown column(s): name")
+	_, err = ListOptions(Text, ConfigColumns, []string{"id", "hostname"}, "", "", false)
+	assert.ErrorContains(t, err, "unknown column(s): hostname (see --columns help)")
+}
+
+func TestPrintQuery(t *testing.T) {
+	data := decode(t, `{"data": [
+		{"id": 1, "hostname": "a.dev.portmap.io", "port_from": 8080, "config": {"name": "office"}},
+		{"id": 2, "hostname": "b.portmap.io", "port_from": 9000, "config": {"name": "lab"}},
+		{"id": 3, "hostname": "c.dev.portmap.io", "port_from": 443, "config": {"name": "lab"}},
+		{"id": 4, "hostname": "d.dev.portmap.io", "port_from": 10000, "config": {"name": "lab"}}
+	]}`)
+
+	filter, err := ParseFilter("port_from>=8000,hostname=*.dev.portmap.io", MappingColumns)
+	require.NoError(t, err)
+	out := capture(t, data, Options{Format: CSV, Columns: []string{"id"}, Filter: filter, NoHeaders: true})
+	assert.Equal(t, "1\n4\n", out)
+
+	// Numbers sort as numbers, and sorting is stable
+	out = capture(t, data, Options{Format: TSV, Columns: []string{"id", "config_name"}, SortBy: "-port_from"})
+	assert.Equal(t, "id\tconfig_name\n4\tlab\n2\tlab\n1\toffice\n3\tlab\n", out)
+	out = capture(t, data, Options{Format: CSV, Columns: []string{"id"}, SortBy: "config_name", NoHeaders: true})
+	assert.Equal(t, "2\n3\n4\n1\n", out)
+
+	out = capture(t, data, Options{Format: Text, Columns: []string{"id", "hostname"}, SortBy: "hostname", NoHeaders: true})
+	assert.Equal(t, "1  a.dev.portmap.io\n2  b.portmap.io\n3  c.dev.portmap.io\n4  d.dev.portmap.io\n", out)
+
+	// The other formats get the filtered list in its response
+	out = capture(t, data, Options{Format: JSONPath + "={.data[*].id}", Filter: []Condition{{Column: "config_name", Op: "=", Value: "office"}}})
+	assert.Equal(t, "1", out)
+}
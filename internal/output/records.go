@@ -0,0 +1,194 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+func printYAML(data interface{}) error {
+	// Going through JSON keeps the field names and order of the JSON output,
+	// and keeps numbers such as IDs out of exponent notation
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	// JSON strings come back double quoted, YAML doesn't need that
+	plainStrings(&node)
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func plainStrings(node *yaml.Node) {
+	if node.Kind == yaml.ScalarNode {
+		node.Style = 0
+	} else {
+		// Flow style is how JSON collections parse, block style reads better
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		plainStrings(child)
+	}
+}
+
+// printDelimited writes the rows of a response as CSV or TSV with a header line
+func printDelimited(data interface{}, opts Options, comma rune) error {
+	return writeDelimited(func(row func(interface{}) error) error {
+		for _, item := range records(data) {
+			if err := row(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts, comma)
+}
+
+// writeDelimited writes the rows each hands over as CSV or TSV, flushing every
+// one. The header line goes first, with the columns that suit the first row.
+func writeDelimited(each func(row func(interface{}) error) error, opts Options, comma rune) error {
+	w := csv.NewWriter(writer)
+	w.Comma = comma
+	var columns, values []string
+	var value valueFunc
+	header := func() error {
+		if opts.NoHeaders {
+			return nil
+		}
+		return w.Write(columns)
+	}
+
+	err := each(func(item interface{}) error {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value == nil {
+			columns, value = columnsFor(row, opts.Columns)
+			values = make([]string, len(columns))
+			if err := header(); err != nil {
+				return err
+			}
+		}
+		for i, column := range columns {
+			values[i] = value(row, column)
+		}
+		if err := w.Write(values); err != nil {
+			return err
+		}
+		w.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		columns = opts.Columns
+		if err := header(); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// printNDJSON writes one compact JSON object per line: every item of a list, or the single object
+func printNDJSON(data interface{}) error {
+	encoder := json.NewEncoder(writer)
+	// Items of a versioned list carry their own apiVersion and kind
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Struct {
+		if items := v.FieldByName("Items"); items.IsValid() {
+			for i := 0; i < items.Len(); i++ {
+				if err := encoder.Encode(items.Index(i).Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return encoder.Encode(data)
+	}
+
+	switch v := generic(unwrap(data)).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return encoder.Encode(v)
+	}
+}
+
+// generic returns typed results, such as a slice of structs, in the form of decoded
+// JSON so they can be walked like API responses
+func generic(data interface{}) interface{} {
+	switch data.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return data
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return data
+	}
+	return v
+}
+
+// unwrap returns the data field of an API response, or data itself when there is none
+func unwrap(data interface{}) interface{} {
+	if wrapper, ok := data.(map[string]interface{}); ok {
+		if field, exists := wrapper["data"]; exists {
+			return field
+		}
+	}
+	return data
+}
+
+// records returns the objects of a response: the items of a list, or the
+// object itself
+func records(data interface{}) []interface{} {
+	switch v := generic(unwrap(data)).(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		return []interface{}{v}
+	}
+	return nil
+}
+
+// columnsFor returns the columns to write for rows like first, and how to
+// format them. Mappings and configs get the columns of their table, other
+// objects all of their keys.
+func columnsFor(first map[string]interface{}, requested []string) ([]string, valueFunc) {
+	if _, ok := first["config"]; ok {
+		return selectColumns(mappingColumns, requested), mappingValue
+	}
+	_, hasName := first["name"]
+	_, hasType := first["type"]
+	if hasName && hasType {
+		return selectColumns(configColumns, requested), configValue
+	}
+
+	keys := make([]string, 0, len(first))
+	for k := range first {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return selectColumns(keys, requested), configValue
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mappingList = `{"data": [
+	{"id": 12345678, "hostname": "a.portmap.io", "protocol": "https", "port_from": 443, "port_to": "8080",
+	 "config": {"id": 3, "name": "office", "type": "WireGuard", "region": "fra1"}},
+	{"id": 2, "hostname": "b.portmap.io", "protocol": "tcp", "port_from": 2222, "port_to": "22", "allowed_ip": null,
+	 "config": {"id": 3, "name": "office, main", "type": "WireGuard", "region": "fra1"}}
+]}`
+
+func capture(t *testing.T, data interface{}, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	defer SetWriter(writer)
+	SetWriter(&buf)
+	require.NoError(t, Print(data, opts))
+	return buf.String()
+}
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("NDJSON")
+	require.NoError(t, err)
+	assert.Equal(t, NDJSON, format)
+
+	_, err = ParseFormat("xml")
+	assert.ErrorContains(t, err, "supported: json, text, yaml, csv, tsv, ndjson")
+}
+
+func TestPrintCSV(t *testing.T) {
+	data := decode(t, mappingList)
+
+	out := capture(t, data, Options{Format: CSV, Columns: []string{"id", "config_name", "port_from"}})
+	assert.Equal(t, "id,config_name,port_from\n12345678,office,443\n2,\"office, main\",2222\n", out)
+
+	out = capture(t, data, Options{Format: TSV, Columns: []string{"hostname", "allowed_ip"}})
+	assert.Equal(t, "hostname\tallowed_ip\na.portmap.io\t-\nb.portmap.io\t-\n", out)
+
+	// Objects that are neither mappings nor configs get all of their keys
+	out = capture(t, map[string]interface{}{"status": "success", "message": "done"}, Options{Format: CSV})
+	assert.Equal(t, "message,status\ndone,success\n", out)
+}
+
+func TestPrintNDJSON(t *testing.T) {
+	out := capture(t, decode(t, mappingList), Options{Format: NDJSON})
+	lines := bytes.Split(bytes.TrimSpace([]byte(out)), []byte("\n"))
+	require.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"id":12345678`)
+
+	type result struct {
+		ID string `json:"id"`
+	}
+	out = capture(t, map[string]interface{}{"data": []result{{"1"}, {"2"}}}, Options{Format: NDJSON})
+	assert.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n", out)
+}
+
+func TestPrintStream(t *testing.T) {
+	stream := func(body io.Reader, opts Options) (string, error) {
+		var buf bytes.Buffer
+		defer SetWriter(writer)
+		SetWriter(&buf)
+		err := PrintStream(body, opts)
+		return buf.String(), err
+	}
+
+	for _, opts := range []Options{
+		{Format: CSV, Columns: []string{"id", "config_name", "port_from"}},
+		{Format: TSV, NoHeaders: true},
+		{Format: NDJSON, Kind: KindMappingList},
+	} {
+		require.True(t, Streams(opts))
+		out, err := stream(strings.NewReader(mappingList), opts)
+		require.NoError(t, err)
+		assert.Equal(t, capture(t, decode(t, mappingList), opts), out, opts.Format)
+	}
+
+	// Each row is written as soon as its item is decoded
+	truncated := io.MultiReader(strings.NewReader(mappingList[:strings.Index(mappingList, "},\n")+2]), iotest.ErrReader(io.ErrUnexpectedEOF))
+	out, err := stream(truncated, Options{Format: CSV, Columns: []string{"id"}})
+	assert.Error(t, err)
+	assert.Equal(t, "id\n12345678\n", out)
+
+	out, err = stream(strings.NewReader(`{"data": []}`), Options{Format: CSV, Columns: []string{"id"}})
+	require.NoError(t, err)
+	assert.Equal(t, "id\n", out)
+
+	assert.False(t, Streams(Options{Format: CSV, SortBy: "id"}))
+	assert.False(t, Streams(Options{Format: JSON}))
+}
+
+func TestPrintYAML(t *testing.T) {
+	out := capture(t, map[string]interface{}{
+		"id":     float64(12345678),
+		"port":   "443",
+		"active": "true",
+		"tags":   []string{"a"},
+	}, Options{Format: YAML})
+	assert.Equal(t, "active: \"true\"\nid: 12345678\nport: \"443\"\ntags:\n  - a\n", out)
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// models are the top level kinds of output and the types describing them
+var models = map[string]interface{}{
+	KindMapping:             Mapping{},
+	KindMappingList:         MappingList{},
+	KindConfig:              Config{},
+	KindConfigList:          ConfigList{},
+	KindRegionList:          RegionList{},
+	KindProbeResultList:     ProbeResultList{},
+	KindMappingDeletionList: MappingDeletionList{},
+	KindStatus:              Status{},
+	KindKeyRotation:         KeyRotation{},
+	KindDryRun:              DryRun{},
+	KindPlan:                Plan{},
+	KindWatchEvent:          WatchEvent{},
+}
+
+// Kinds lists the kinds of output, sorted
+func Kinds() []string {
+	kinds := make([]string, 0, len(models))
+	for kind := range models {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Schema returns the JSON Schema of a kind of output, generated from its model
+func Schema(kind string) ([]byte, error) {
+	model, ok := models[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown output kind: %s", kind)
+	}
+	schema := schemaOf(reflect.TypeOf(model))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = kind
+
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaOf(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() == reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case t.Kind() == reflect.Struct:
+		return structSchema(t)
+	}
+	// interface{} holds any value
+	return map[string]interface{}{}
+}
+
+// structSchema describes the fields of a struct, with those of an embedded
+// TypeMeta fixed to the kind the struct is named after
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == reflect.TypeOf(TypeMeta{}) {
+			properties["apiVersion"] = map[string]interface{}{"const": APIVersion}
+			properties["kind"] = map[string]interface{}{"const": t.Name()}
+			required = append(required, "apiVersion", "kind")
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = schemaOf(field.Type)
+		if options != "omitempty" {
+			required = append(required, name)
+		}
+	}
+
+	// Fields may be added within a version, so others are allowed
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
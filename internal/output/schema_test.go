@@ -0,0 +1,52 @@
+package output
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate the JSON Schema files")
+
+// schemaDir holds the published schemas, which go test -update regenerates
+var schemaDir = filepath.Join("..", "..", "schemas", "portmap-v1")
+
+func TestSchemas(t *testing.T) {
+	for _, kind := range Kinds() {
+		schema, err := Schema(kind)
+		require.NoError(t, err)
+
+		file := filepath.Join(schemaDir, kind+".json")
+		if *update {
+			require.NoError(t, os.MkdirAll(schemaDir, 0o755))
+			require.NoError(t, os.WriteFile(file, schema, 0o644))
+			continue
+		}
+		published, err := os.ReadFile(file)
+		require.NoError(t, err, "run go test ./internal/output -update")
+		assert.Equal(t, string(published), string(schema), "%s is out of date, run go test ./internal/output -update", file)
+	}
+}
+
+func TestSchemaRequiredFields(t *testing.T) {
+	schema := decode(t, mustSchema(t, KindMapping)).(map[string]interface{})
+	required := schema["required"].([]interface{})
+	assert.Contains(t, required, "apiVersion")
+	assert.Contains(t, required, "port_from")
+	assert.NotContains(t, required, "hostheader")
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"const": "Mapping"}, properties["kind"])
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, properties["id"])
+}
+
+func mustSchema(t *testing.T, kind string) string {
+	t.Helper()
+	schema, err := Schema(kind)
+	require.NoError(t, err)
+	return string(schema)
+}
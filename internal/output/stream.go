@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Streams reports whether list responses printed with opts can be written item
+// by item as they arrive: CSV, TSV and NDJSON can, unless filtering or sorting
+// needs every item first
+func Streams(opts Options) bool {
+	switch opts.Format {
+	case CSV, TSV, NDJSON:
+		return len(opts.Filter) == 0 && opts.SortBy == ""
+	}
+	return false
+}
+
+// PrintStream prints the response read from r like Print, writing every item of
+// a list as soon as it is decoded. Only options for which Streams is true are
+// supported.
+func PrintStream(r io.Reader, opts Options) error {
+	if !Streams(opts) {
+		return fmt.Errorf("output format %s can't be streamed", opts.Format)
+	}
+	if opts.Format == NDJSON {
+		encoder := json.NewEncoder(writer)
+		return eachItem(r, func(item interface{}) error {
+			return encoder.Encode(listItem(item, opts.Kind))
+		})
+	}
+	comma := ','
+	if opts.Format == TSV {
+		comma = '\t'
+	}
+	return writeDelimited(func(row func(interface{}) error) error {
+		return eachItem(r, row)
+	}, opts, comma)
+}
+
+// listItem converts an item of a list of kind to its model, as envelope does
+// for whole lists
+func listItem(item interface{}, kind string) interface{} {
+	switch kind {
+	case KindMappingList:
+		return toMapping(object(item))
+	case KindConfigList:
+		return toConfig(object(item))
+	}
+	return item
+}
+
+// eachItem decodes a response from r and hands its items to fn one at a time:
+// the elements of its data field or of the response itself when it is a list,
+// and else the single object, as unwrap would return it
+func eachItem(r io.Reader, fn func(interface{}) error) error {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case json.Delim('['):
+		return eachElement(decoder, fn)
+	case json.Delim('{'):
+		wrapper := make(map[string]interface{})
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			if key == "data" {
+				token, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				if token == json.Delim('[') {
+					return eachElement(decoder, fn)
+				}
+				data, err := rest(decoder, token)
+				if err != nil {
+					return err
+				}
+				return fn(data)
+			}
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return err
+			}
+			wrapper[key.(string)] = value
+		}
+		return fn(wrapper)
+	}
+	return fn(token)
+}
+
+// eachElement hands the elements of the array the decoder is in to fn, and
+// reads its end
+func eachElement(decoder *json.Decoder, fn func(interface{}) error) error {
+	for decoder.More() {
+		var item interface{}
+		if err := decoder.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// rest decodes a value other than an array whose first token has already
+// been read
+func rest(decoder *json.Decoder, token json.Token) (interface{}, error) {
+	if token != json.Delim('{') {
+		return token, nil
+	}
+	object := make(map[string]interface{})
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		object[key.(string)] = value
+	}
+	_, err := decoder.Token()
+	return object, err
+}
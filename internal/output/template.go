@@ -0,0 +1,79 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+)
+
+// templateFuncs are available to go-template output on top of the builtin functions
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func checkTemplate(format Format, text string) error {
+	var err error
+	if format == GoTemplate {
+		_, err = template.New("output").Funcs(templateFuncs).Parse(text)
+	} else {
+		_, err = parseJSONPath(text)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s template: %w", format, err)
+	}
+	return nil
+}
+
+// printGoTemplate executes a Go template with the data of the response as dot:
+// the list of a list command, or the object of a show or create command
+func printGoTemplate(data interface{}, text string) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid go-template template: %w", err)
+	}
+	value, err := decoded(unwrap(data))
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(writer, value); err != nil {
+		return fmt.Errorf("failed to execute go-template: %w", err)
+	}
+	return nil
+}
+
+// decoded returns data as decoded JSON, with typed results turned into objects
+// and numbers kept as written, so IDs such as 12345678 don't print as 1.2345678e+07
+func decoded(data interface{}) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// printJSONPath executes a kubectl style JSONPath template against the whole
+// response, as in {.data[*].id}
+func printJSONPath(data interface{}, text string) error {
+	path, err := parseJSONPath(text)
+	if err != nil {
+		return fmt.Errorf("invalid jsonpath template: %w", err)
+	}
+	value, err := decoded(data)
+	if err != nil {
+		return err
+	}
+	if err := path.execute(writer, value); err != nil {
+		return fmt.Errorf("failed to execute jsonpath: %w", err)
+	}
+	return nil
+}
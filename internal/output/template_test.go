@@ -0,0 +1,66 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTemplateFormat(t *testing.T) {
+	format, err := ParseFormat("JSONPath={.data[*].ID}")
+	require.NoError(t, err)
+	assert.Equal(t, JSONPath, format.Name())
+	assert.Equal(t, "{.data[*].ID}", format.Template())
+
+	_, err = ParseFormat("go-template")
+	assert.ErrorContains(t, err, "needs a template")
+	_, err = ParseFormat("go-template={{.id")
+	assert.ErrorContains(t, err, "invalid go-template template")
+	_, err = ParseFormat("jsonpath={range .data[*]}")
+	assert.ErrorContains(t, err, "{range} without {end}")
+	_, err = ParseFormat("json=x")
+	assert.ErrorContains(t, err, "takes no template")
+}
+
+func TestPrintGoTemplate(t *testing.T) {
+	data := decode(t, mappingList)
+
+	out := capture(t, data, Options{Format: "go-template={{range .}}{{.id}} {{.hostname}}\n{{end}}"})
+	assert.Equal(t, "12345678 a.portmap.io\n2 b.portmap.io\n", out)
+
+	single := decode(t, `{"data": {"id": 7, "hostname": "a.portmap.io", "config": {"name": "office"}}}`)
+	out = capture(t, single, Options{Format: "go-template={{.hostname}} {{json .config}}"})
+	assert.Equal(t, `a.portmap.io {"name":"office"}`, out)
+}
+
+func TestPrintJSONPath(t *testing.T) {
+	data := decode(t, mappingList)
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"{.data[*].id}", "12345678 2"},
+		{".data[0].hostname", "a.portmap.io"},
+		{"{.data[-1].config.name}", "office, main"},
+		{"{.data[0:1].port_to}", "8080"},
+		{"{..hostname}", "a.portmap.io b.portmap.io"},
+		{"{.data[?(@.protocol=='tcp')].id}", "2"},
+		{"{.data[?(@.port_to>1000)].hostname}", "a.portmap.io"},
+		{"{.data[?(@.allowed_ip)].id}", "2"},
+		{"{.data[0].config}", `{"id":3,"name":"office","region":"fra1","type":"WireGuard"}`},
+		{`{range .data[*]}{.hostname}:{.port_from}{"\n"}{end}`, "a.portmap.io:443\nb.portmap.io:2222\n"},
+		{`{range .data[*]}{$.data[0].id}-{@.id} {end}`, "12345678-12345678 12345678-2 "},
+		{"{.data[*].missing}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			out := capture(t, data, Options{Format: JSONPath + "=" + Format(tt.template)})
+			assert.Equal(t, tt.want, out)
+		})
+	}
+
+	err := Print(data, Options{Format: "jsonpath={.data[5]}"})
+	assert.ErrorContains(t, err, "out of bounds")
+}
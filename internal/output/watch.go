@@ -0,0 +1,301 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Event types of watch output
+const (
+	Added    = "ADDED"
+	Modified = "MODIFIED"
+	Deleted  = "DELETED"
+)
+
+const (
+	colorAdded    = "\033[32m"
+	colorModified = "\033[33m"
+	colorDeleted  = "\033[31m"
+	colorReset    = "\033[0m"
+)
+
+// Watch calls fetch every interval until interrupted. Text output redraws the
+// table with the rows added, changed or removed since the previous poll
+// highlighted; JSON and NDJSON output print one event per change. Only the
+// first fetch failing is fatal, later failures are reported and retried.
+func Watch(title string, interval time.Duration, fetch func() (interface{}, error), opts Options) error {
+	switch opts.Format.Name() {
+	case Text, JSON, NDJSON:
+	default:
+		return fmt.Errorf("--watch supports text, json and ndjson output")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := &watcher{title: title, interval: interval, opts: opts, tty: isTerminal(writer)}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for polls := 0; ; polls++ {
+		data, err := fetch()
+		switch {
+		case err != nil && polls == 0:
+			return err
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		default:
+			if err := w.update(data, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// watcher keeps what the previous poll returned, keyed by item ID for lists
+type watcher struct {
+	title    string
+	interval time.Duration
+	opts     Options
+	tty      bool
+
+	polled   bool
+	previous map[string]interface{}
+	order    []string
+	lines    map[string]string
+}
+
+func (w *watcher) update(data interface{}, now time.Time) error {
+	data = query(data, w.opts)
+	current, order, isList := keyed(unwrap(data))
+
+	// On the first poll every item is an ADDED event, as with kubectl get --watch
+	if w.opts.Format.Name() != Text {
+		events, err := w.diff(current, order, now)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err := Print(e, Options{Format: w.opts.Format}); err != nil {
+				return err
+			}
+		}
+	} else if err := w.draw(data, current, order, isList, now); err != nil {
+		return err
+	}
+
+	w.polled = true
+	w.previous, w.order = current, order
+	return nil
+}
+
+// keyed indexes the items of a list by ID, or returns a single object under ""
+func keyed(data interface{}) (map[string]interface{}, []string, bool) {
+	items := make(map[string]interface{})
+	var order []string
+	list, ok := data.([]interface{})
+	if !ok {
+		items[""] = data
+		return items, []string{""}, false
+	}
+	for i, item := range list {
+		key := fmt.Sprintf("#%d", i)
+		if row, ok := item.(map[string]interface{}); ok && row["id"] != nil {
+			key = formatValue(row["id"])
+		}
+		items[key] = item
+		order = append(order, key)
+	}
+	return items, order, true
+}
+
+func same(a, b interface{}) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(x, y)
+}
+
+// diff lists the events between the previous poll and current, in list order
+// with deletions last. Objects are converted to the model of the items of the
+// watched kind.
+func (w *watcher) diff(current map[string]interface{}, order []string, now time.Time) ([]WatchEvent, error) {
+	var events []WatchEvent
+	add := func(eventType string, object interface{}) error {
+		model, err := envelope(object, strings.TrimSuffix(w.opts.Kind, "List"))
+		if err != nil {
+			return err
+		}
+		events = append(events, WatchEvent{TypeMeta: typeMeta(KindWatchEvent), Type: eventType, Time: now, Object: model})
+		return nil
+	}
+
+	for _, key := range order {
+		old, existed := w.previous[key]
+		var err error
+		switch {
+		case !existed:
+			err = add(Added, current[key])
+		case !same(old, current[key]):
+			err = add(Modified, current[key])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range w.order {
+		if _, exists := current[key]; !exists {
+			if err := add(Deleted, w.previous[key]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return events, nil
+}
+
+func (w *watcher) draw(data interface{}, current map[string]interface{}, order []string, isList bool, now time.Time) error {
+	// Filtering and sorting happened in update
+	opts := w.opts
+	opts.Filter, opts.SortBy = nil, ""
+
+	var frame strings.Builder
+	if w.tty {
+		// Move home and clear the screen, so the table is redrawn in place
+		frame.WriteString("\033[H\033[2J")
+	} else if w.polled {
+		frame.WriteString("\n")
+	}
+	fmt.Fprintf(&frame, "Every %s: %s    %s\n\n", w.interval, w.title, now.Format("2006-01-02 15:04:05"))
+
+	var lines, states []string
+	if !isList {
+		rendered, err := render(data, opts)
+		if err != nil {
+			return err
+		}
+		lines = rendered
+		states = w.lineStates(lines)
+	} else {
+		// Removed rows stay in the table for one poll, after the others
+		rows := make([]interface{}, 0, len(order))
+		for _, key := range order {
+			rows = append(rows, current[key])
+		}
+		for _, key := range w.order {
+			if _, exists := current[key]; !exists {
+				rows = append(rows, w.previous[key])
+			}
+		}
+		rendered, err := render(map[string]interface{}{"data": rows}, opts)
+		if err != nil {
+			return err
+		}
+		lines = rendered
+		states = make([]string, len(lines))
+		header := 2
+		if opts.NoHeaders || len(rows) == 0 {
+			header = 0
+		}
+		for i, key := range order {
+			if w.polled && header+i < len(states) {
+				old, existed := w.previous[key]
+				switch {
+				case !existed:
+					states[header+i] = Added
+				case !same(old, current[key]):
+					states[header+i] = Modified
+				}
+			}
+		}
+		for i := header + len(order); i < len(states); i++ {
+			states[i] = Deleted
+		}
+	}
+
+	for i, line := range lines {
+		frame.WriteString(w.mark(line, states[i]))
+		frame.WriteString("\n")
+	}
+	_, err := io.WriteString(writer, frame.String())
+	return err
+}
+
+// lineStates compares the KEY VALUE lines of a single object with the previous
+// poll, by key. Multi-line values, such as a config file, repeat keys between
+// blank lines, so the nth line with a key is compared with the nth one before.
+func (w *watcher) lineStates(lines []string) []string {
+	states := make([]string, len(lines))
+	previous := w.lines
+	w.lines = make(map[string]string)
+	seen := make(map[string]int)
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		seen[fields[0]]++
+		key := fmt.Sprintf("%s#%d", fields[0], seen[fields[0]])
+		w.lines[key] = line
+		if !w.polled {
+			continue
+		}
+		old, existed := previous[key]
+		switch {
+		case !existed:
+			states[i] = Added
+		case old != line:
+			states[i] = Modified
+		}
+	}
+	return states
+}
+
+// mark colors a changed line on a terminal, and prefixes it with +, ~ or - otherwise
+func (w *watcher) mark(line, state string) string {
+	if w.tty {
+		color := map[string]string{Added: colorAdded, Modified: colorModified, Deleted: colorDeleted}[state]
+		if color == "" {
+			return line
+		}
+		return color + line + colorReset
+	}
+	symbol := map[string]string{Added: "+ ", Modified: "~ ", Deleted: "- "}[state]
+	if symbol == "" {
+		symbol = "  "
+	}
+	return symbol + line
+}
+
+// render returns the text table of data as lines
+func render(data interface{}, opts Options) ([]string, error) {
+	var buf bytes.Buffer
+	saved := writer
+	writer = &buf
+	err := printText(data, opts)
+	writer = saved
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
+}
@@ -0,0 +1,80 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchEvents(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetWriter(writer)
+	SetWriter(&buf)
+
+	w := &watcher{opts: Options{Format: NDJSON, Kind: KindMappingList}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, w.update(decode(t, `{"data": [{"id": 1, "active": false}, {"id": 2, "active": true}]}`), now))
+	require.NoError(t, w.update(decode(t, `{"data": [{"id": 1, "active": true}, {"id": 3, "active": true}]}`), now))
+	require.NoError(t, w.update(decode(t, `{"data": [{"id": 1, "active": true}, {"id": 3, "active": true}]}`), now))
+
+	var types []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		event := decode(t, line).(map[string]interface{})
+		object := event["object"].(map[string]interface{})
+		assert.Equal(t, KindWatchEvent, event["kind"])
+		assert.Equal(t, KindMapping, object["kind"])
+		types = append(types, event["type"].(string)+" "+formatValue(object["id"]))
+	}
+	assert.Equal(t, []string{"ADDED 1", "ADDED 2", "MODIFIED 1", "ADDED 3", "DELETED 2"}, types)
+}
+
+func TestWatchText(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetWriter(writer)
+	SetWriter(&buf)
+
+	w := &watcher{title: "portmap config list", interval: time.Second, opts: Options{Format: Text, Columns: []string{"id", "name"}}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, w.update(decode(t, `{"data": [{"id": 1, "name": "a", "type": "SSH"}, {"id": 2, "name": "b", "type": "SSH"}]}`), now))
+	buf.Reset()
+	require.NoError(t, w.update(decode(t, `{"data": [{"id": 1, "name": "c", "type": "SSH"}, {"id": 3, "name": "d", "type": "SSH"}]}`), now))
+
+	assert.Equal(t, "\nEvery 1s: portmap config list    2026-01-02 03:04:05\n\n"+
+		"  id   name\n"+
+		"  ---  ---  \n"+
+		"~ 1    c\n"+
+		"+ 3    d\n"+
+		"- 2    b\n", buf.String())
+}
+
+func TestWatchMultilineValue(t *testing.T) {
+	var buf bytes.Buffer
+	defer SetWriter(writer)
+	SetWriter(&buf)
+
+	w := &watcher{title: "portmap config show 1", interval: time.Second, opts: Options{Format: Text}}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	show := func(endpoint string) {
+		file := "[Interface]\nAddress = 10.0.0.2/32\n\n[Peer]\nEndpoint = " + endpoint + "\n"
+		require.NoError(t, w.update(map[string]interface{}{"data": map[string]interface{}{"id": 1, "config_file": file}}, now))
+	}
+	// Blank lines between the sections of the file have no key
+	show("fra1.portmap.io:51820")
+	buf.Reset()
+	show("nyc1.portmap.io:51820")
+
+	assert.Equal(t, "\nEvery 1s: portmap config show 1    2026-01-02 03:04:05\n\n"+
+		"  KEY          VALUE\n"+
+		"  ---          -----\n"+
+		"  config_file  [Interface]\n"+
+		"  Address = 10.0.0.2/32\n"+
+		"  \n"+
+		"  [Peer]\n"+
+		"~ Endpoint = nyc1.portmap.io:51820\n"+
+		"  \n"+
+		"  id  1\n", buf.String())
+}
@@ -0,0 +1,160 @@
+package regions
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ProbeOptions controls how regions are probed
+type ProbeOptions struct {
+	// Network is "tcp" (connect handshake) or "udp" (datagram answered by an ICMP port unreachable)
+	Network string
+	// Port overrides the default port of the network
+	Port string
+	// Attempts is the number of round trips per region; the fastest one counts
+	Attempts int
+	Timeout  time.Duration
+}
+
+// DefaultProbeOptions are used by Nearest and by `portmap regions probe` without flags
+var DefaultProbeOptions = ProbeOptions{Network: "tcp", Attempts: 3, Timeout: 2 * time.Second}
+
+// Default probe ports: the HTTPS endpoint for TCP, and a traceroute port nothing listens on for UDP
+const (
+	tcpProbePort = "443"
+	udpProbePort = "33434"
+)
+
+// Result is the measured round-trip time to a region
+type Result struct {
+	Region Region
+	RTT    time.Duration
+	Err    error
+}
+
+// Probe measures the round-trip time to every region in list concurrently and
+// returns the results fastest first, with unreachable regions last
+func Probe(list []Region, opts ProbeOptions) ([]Result, error) {
+	port := opts.Port
+	switch opts.Network {
+	case "tcp":
+		if port == "" {
+			port = tcpProbePort
+		}
+	case "udp":
+		if port == "" {
+			port = udpProbePort
+		}
+	default:
+		return nil, fmt.Errorf("invalid probe network: %s (supported: tcp, udp)", opts.Network)
+	}
+	if opts.Attempts < 1 {
+		opts.Attempts = 1
+	}
+
+	results := make([]Result, len(list))
+	var wg sync.WaitGroup
+	for i, r := range list {
+		wg.Add(1)
+		go func(i int, r Region) {
+			defer wg.Done()
+			results[i] = probeRegion(r, port, opts)
+		}(i, r)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Err == nil && results[i].RTT < results[j].RTT
+	})
+	return results, nil
+}
+
+// Nearest returns the region with the lowest round-trip time, if any answered
+func Nearest() (Region, bool) {
+	results, err := Probe(All(), DefaultProbeOptions)
+	if err != nil || len(results) == 0 || results[0].Err != nil {
+		return Region{}, false
+	}
+	return results[0].Region, true
+}
+
+func probeRegion(r Region, port string, opts ProbeOptions) Result {
+	result := Result{Region: r}
+	host := r.Hostname
+	if host == "" {
+		host = Hostname(r.Name)
+	}
+	addr := net.JoinHostPort(host, port)
+
+	for i := 0; i < opts.Attempts; i++ {
+		var rtt time.Duration
+		var err error
+		if opts.Network == "udp" {
+			rtt, err = probeUDP(addr, opts.Timeout)
+		} else {
+			rtt, err = probeTCP(addr, opts.Timeout)
+		}
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		if result.RTT == 0 || rtt < result.RTT {
+			result.RTT = rtt
+		}
+	}
+
+	// One answered round trip is enough to rank the region
+	if result.RTT > 0 {
+		result.Err = nil
+	}
+	return result
+}
+
+func probeTCP(addr string, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, nil
+}
+
+// probeUDP sends a datagram to a closed port; the ICMP port unreachable that comes
+// back surfaces as ECONNREFUSED, and either that or a real reply completes the round trip
+func probeUDP(addr string, timeout time.Duration) (time.Duration, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return 0, err
+	}
+	conn, err := net.DialUDP("udp", nil, raddr)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return 0, err
+	}
+	conn.SetReadDeadline(start.Add(timeout))
+
+	buf := make([]byte, 1)
+	_, err = conn.Read(buf)
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return 0, fmt.Errorf("no reply from %s within %s", addr, timeout)
+	}
+	if err != nil && !errors.Is(err, syscall.ECONNREFUSED) {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
@@ -0,0 +1,42 @@
+package regions
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	list := []Region{{Name: "local", Hostname: "127.0.0.1"}}
+	results, err := Probe(list, ProbeOptions{Network: "tcp", Port: port, Attempts: 2, Timeout: time.Second})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "local", results[0].Region.Name)
+	assert.Greater(t, results[0].RTT, time.Duration(0))
+
+	// Closed loopback ports answer UDP with a port unreachable
+	results, err = Probe(list, ProbeOptions{Network: "udp", Attempts: 1, Timeout: time.Second})
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+
+	_, err = Probe(list, ProbeOptions{Network: "icmp"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package regions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CacheTTL is how long a fetched region catalog is used before it is refreshed
+const CacheTTL = 24 * time.Hour
+
+// RefreshTimeout is how long the refresh run before every command may take
+const RefreshTimeout = 3 * time.Second
+
+// RetryAfter is how long a failed refresh is not tried again, so an unreachable
+// API doesn't slow down every command
+const RetryAfter = time.Hour
+
+// Region is a portmap.io region and the host serving it
+type Region struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Label    string `json:"label,omitempty"`
+}
+
+// Lister fetches the region catalog, implemented by api.Client
+type Lister interface {
+	ListRegions() (interface{}, error)
+}
+
+// builtin is used until a catalog has been fetched from the API
+var builtin = []Region{
+	{Name: "default", Hostname: "portmap.io", Label: "Default"},
+	{Name: "nyc1", Hostname: "nyc1.portmap.io", Label: "New York"},
+	{Name: "fra1", Hostname: "fra1.portmap.io", Label: "Frankfurt"},
+	{Name: "blr1", Hostname: "blr1.portmap.io", Label: "Bangalore"},
+	{Name: "sin1", Hostname: "sin1.portmap.io", Label: "Singapore"},
+}
+
+var current = builtin
+
+type cacheFile struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	FailedAt  time.Time `json:"failed_at,omitempty"`
+	Regions   []Region  `json:"regions"`
+}
+
+// All returns the regions of the current catalog
+func All() []Region {
+	return current
+}
+
+// Names returns the names of all regions
+func Names() []string {
+	names := make([]string, len(current))
+	for i, r := range current {
+		names[i] = r.Name
+	}
+	return names
+}
+
+// Lookup returns the region called name
+func Lookup(name string) (Region, bool) {
+	for _, r := range current {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// Hostname returns the host serving region; an empty region means the default one
+func Hostname(region string) string {
+	if region == "" {
+		region = "default"
+	}
+	if r, ok := Lookup(region); ok && r.Hostname != "" {
+		return r.Hostname
+	}
+	if region == "default" {
+		return "portmap.io"
+	}
+	return fmt.Sprintf("%s.portmap.io", region)
+}
+
+// APIURL returns the base URL of the API served by region
+func APIURL(region string) string {
+	return fmt.Sprintf("https://%s/api", Hostname(region))
+}
+
+// FlagUsage appends the known regions to a flag description
+func FlagUsage(description string) string {
+	return fmt.Sprintf("%s (%s)", description, strings.Join(Names(), ", "))
+}
+
+// Set replaces the current catalog
+func Set(list []Region) {
+	if len(list) > 0 {
+		current = list
+	}
+}
+
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "portmap", "regions.json"), nil
+}
+
+func readCache() (*cacheFile, error) {
+	path, err := cachePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache cacheFile
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// LoadCache makes the last fetched catalog current, if there is one. It never
+// touches the network, so it is cheap enough to run before building commands.
+func LoadCache() {
+	if cache, err := readCache(); err == nil {
+		Set(cache.Regions)
+	}
+}
+
+// Refresh fetches the catalog through client when the cached one is missing or
+// older than CacheTTL (or always, with force) and stores it in the cache. A
+// failed attempt is recorded too, and not repeated within RetryAfter.
+func Refresh(client Lister, force bool) error {
+	cache, _ := readCache()
+	if !force && cache != nil {
+		if time.Since(cache.FetchedAt) < CacheTTL || time.Since(cache.FailedAt) < RetryAfter {
+			return nil
+		}
+	}
+
+	response, err := client.ListRegions()
+	var list []Region
+	if err == nil {
+		list, err = parse(response)
+	}
+	if err != nil {
+		// The last catalog stays in use
+		failed := cacheFile{FailedAt: time.Now()}
+		if cache != nil {
+			failed.FetchedAt, failed.Regions = cache.FetchedAt, cache.Regions
+		}
+		writeCache(failed)
+		return fmt.Errorf("failed to fetch regions: %w", err)
+	}
+	Set(list)
+	return writeCache(cacheFile{FetchedAt: time.Now(), Regions: list})
+}
+
+func writeCache(cache cacheFile) error {
+	path, err := cachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// parse reads the regions endpoint response: {"data": [{"name": "fra1", "hostname": "fra1.portmap.io"}, ...]}
+func parse(response interface{}) ([]Region, error) {
+	data := response
+	if wrapper, ok := response.(map[string]interface{}); ok {
+		data = wrapper["data"]
+	}
+	items, ok := data.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("invalid regions response")
+	}
+
+	list := make([]Region, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			list = append(list, Region{Name: v})
+		case map[string]interface{}:
+			r := Region{}
+			r.Name, _ = v["name"].(string)
+			r.Hostname, _ = v["hostname"].(string)
+			r.Label, _ = v["label"].(string)
+			if r.Name == "" {
+				continue
+			}
+			list = append(list, r)
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("invalid regions response")
+	}
+
+	for i := range list {
+		if list[i].Hostname == "" {
+			if list[i].Name == "default" {
+				list[i].Hostname = "portmap.io"
+			} else {
+				list[i].Hostname = list[i].Name + ".portmap.io"
+			}
+		}
+	}
+	return list, nil
+}
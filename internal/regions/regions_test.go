@@ -0,0 +1,70 @@
+package regions
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLister struct {
+	response interface{}
+	err      error
+	calls    int
+}
+
+func (f *fakeLister) ListRegions() (interface{}, error) {
+	f.calls++
+	return f.response, f.err
+}
+
+func TestHostname(t *testing.T) {
+	assert.Equal(t, "portmap.io", Hostname(""))
+	assert.Equal(t, "portmap.io", Hostname("default"))
+	assert.Equal(t, "fra1.portmap.io", Hostname("fra1"))
+	assert.Equal(t, "https://sin1.portmap.io/api", APIURL("sin1"))
+	assert.Equal(t, "Region (default, nyc1, fra1, blr1, sin1)", FlagUsage("Region"))
+}
+
+func TestRefresh(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer Set(builtin)
+
+	lister := &fakeLister{response: map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"name": "default"},
+			map[string]interface{}{"name": "ams1", "hostname": "ams1.edge.portmap.io", "label": "Amsterdam"},
+		},
+	}}
+
+	require.NoError(t, Refresh(lister, false))
+	assert.Equal(t, []string{"default", "ams1"}, Names())
+	assert.Equal(t, "portmap.io", Hostname("default"))
+	assert.Equal(t, "ams1.edge.portmap.io", Hostname("ams1"))
+
+	// A fresh cache is not fetched again, but is picked up by LoadCache
+	Set(builtin)
+	require.NoError(t, Refresh(lister, false))
+	assert.Equal(t, 1, lister.calls)
+	LoadCache()
+	assert.Equal(t, []string{"default", "ams1"}, Names())
+
+	require.NoError(t, Refresh(lister, true))
+	assert.Equal(t, 2, lister.calls)
+
+	assert.Error(t, Refresh(&fakeLister{response: map[string]interface{}{"data": []interface{}{}}}, true))
+
+	// A failed refresh keeps the catalog and isn't retried for a while
+	down := &fakeLister{err: errors.New("connection refused")}
+	cache, err := readCache()
+	require.NoError(t, err)
+	cache.FetchedAt, cache.FailedAt = time.Now().Add(-2*CacheTTL), time.Time{}
+	require.NoError(t, writeCache(*cache))
+	assert.Error(t, Refresh(down, false))
+	require.NoError(t, Refresh(down, false))
+	assert.Equal(t, 1, down.calls)
+	LoadCache()
+	assert.Equal(t, []string{"default", "ams1"}, Names())
+}
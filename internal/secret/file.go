@@ -0,0 +1,189 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+	"portmap.io/client/internal/input"
+)
+
+const (
+	// FileEnvVar overrides the location of the encrypted secrets file
+	FileEnvVar = "PORTMAP_SECRET_FILE"
+
+	// PassphraseEnvVar holds the passphrase of the encrypted secrets file for
+	// non-interactive use; otherwise it is prompted for
+	PassphraseEnvVar = "PORTMAP_SECRET_PASSPHRASE"
+)
+
+// fileStore keeps secrets in a single AES-GCM encrypted JSON file, used where
+// no OS keyring is available
+type fileStore struct {
+	path       string
+	passphrase string
+}
+
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func newFileStore() (*fileStore, error) {
+	path := os.Getenv(FileEnvVar)
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate config directory: %w", err)
+		}
+		path = filepath.Join(dir, "portmap", "secrets.enc")
+	}
+	return &fileStore{path: path}, nil
+}
+
+func (s *fileStore) Name() string {
+	return "file"
+}
+
+func (s *fileStore) Get(key string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	return secrets[key], nil
+}
+
+func (s *fileStore) Set(key, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *fileStore) Delete(key string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *fileStore) getPassphrase() (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+
+	passphrase := os.Getenv(PassphraseEnvVar)
+	if passphrase == "" {
+		var err error
+		passphrase, err = input.ReadSecret("Secret store passphrase")
+		if err != nil {
+			return "", fmt.Errorf("%w (set %s for non-interactive use)", err, PassphraseEnvVar)
+		}
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("secret store passphrase cannot be empty")
+	}
+
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+func (s *fileStore) newAEAD(salt []byte) (cipher.AEAD, error) {
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	raw, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	aead, err := s.newAEAD(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets file: wrong passphrase?")
+	}
+
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+func (s *fileStore) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Version: 1,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	aead, err := s.newAEAD(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = aead.Seal(nil, file.Nonce, plain, nil)
+
+	raw, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	if err := os.WriteFile(s.path, raw, 0600); err != nil {
+		return fmt.Errorf("failed to save secrets file: %w", err)
+	}
+	return nil
+}
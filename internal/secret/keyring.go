@@ -0,0 +1,54 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const serviceName = "portmap"
+
+// keyringStore keeps secrets in the Secret Service (GNOME Keyring, KWallet)
+// over D-Bus, through libsecret's secret-tool
+type keyringStore struct{}
+
+func keyringAvailable() bool {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return false
+	}
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+func (s *keyringStore) Name() string {
+	return "keyring"
+}
+
+func (s *keyringStore) Get(key string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", serviceName, "key", key).Output()
+	if err != nil {
+		// secret-tool exits non-zero without output when the item does not exist
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s from keyring: %v", key, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (s *keyringStore) Set(key, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", "portmap.io "+key, "service", serviceName, "key", key)
+	cmd.Stdin = strings.NewReader(value)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store %s in keyring: %v: %s", key, err, out)
+	}
+	return nil
+}
+
+func (s *keyringStore) Delete(key string) error {
+	if out, err := exec.Command("secret-tool", "clear", "service", serviceName, "key", key).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete %s from keyring: %v: %s", key, err, out)
+	}
+	return nil
+}
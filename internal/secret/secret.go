@@ -0,0 +1,155 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BackendEnvVar selects where secrets are kept (keyring, file or auto)
+const BackendEnvVar = "PORTMAP_SECRET_BACKEND"
+
+// backends are the stores a config value can point into instead of holding
+// the secret itself, named by the prefix of the value, e.g.
+// PORTMAP_TOKEN=keyring:token or PORTMAP_TOKEN=file:token
+var backends = []string{"keyring", "file"}
+
+// Store keeps secrets outside of plaintext config files
+type Store interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, value string) error
+	Delete(key string) error
+}
+
+// Opened stores are reused so the file backend asks for its passphrase only once
+var stores = make(map[string]Store)
+
+// Open returns the store selected by PORTMAP_SECRET_BACKEND. In auto mode the
+// OS keyring is used when available, otherwise the encrypted file.
+func Open() (Store, error) {
+	return openBackend(strings.ToLower(os.Getenv(BackendEnvVar)))
+}
+
+func openBackend(backend string) (Store, error) {
+	if store, ok := stores[backend]; ok {
+		return store, nil
+	}
+
+	var store Store
+	switch backend {
+	case "keyring":
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("keyring backend requires secret-tool and a D-Bus session")
+		}
+		store = &keyringStore{}
+	case "file":
+		fs, err := newFileStore()
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	case "", "auto":
+		if keyringAvailable() {
+			store = &keyringStore{}
+			break
+		}
+		fs, err := newFileStore()
+		if err != nil {
+			return nil, err
+		}
+		store = fs
+	default:
+		return nil, fmt.Errorf("invalid %s: %s (supported: keyring, file, auto)", BackendEnvVar, backend)
+	}
+
+	stores[backend] = store
+	return store, nil
+}
+
+// Configured reports whether a secret backend has been set up, in which case
+// new secrets should be stored there rather than in plaintext
+func Configured() bool {
+	return os.Getenv(BackendEnvVar) != ""
+}
+
+// Ref returns the config value that refers to key in store
+func Ref(store Store, key string) string {
+	return store.Name() + ":" + key
+}
+
+// ParseRef returns the backend and key a config value refers to, and whether
+// it is a reference at all
+func ParseRef(value string) (backend, key string, ok bool) {
+	backend, key, found := strings.Cut(value, ":")
+	if !found {
+		return "", "", false
+	}
+	for _, b := range backends {
+		if backend == b {
+			return backend, key, true
+		}
+	}
+	return "", "", false
+}
+
+// IsRef reports whether value refers to the secret store
+func IsRef(value string) bool {
+	_, _, ok := ParseRef(value)
+	return ok
+}
+
+// OpenRef returns the store a reference points into, whichever backend is
+// selected now, so references keep working after switching backends
+func OpenRef(value string) (Store, string, error) {
+	backend, key, ok := ParseRef(value)
+	if !ok {
+		return nil, "", fmt.Errorf("%s is not a secret reference", value)
+	}
+	// The encrypted file also wrote keyring: references before they were
+	// named by their backend
+	if backend == "keyring" && !keyringAvailable() {
+		backend = "file"
+	}
+	store, err := openBackend(backend)
+	if err != nil {
+		return nil, "", err
+	}
+	return store, key, nil
+}
+
+// Resolved secrets are kept for the lifetime of the process, since the config
+// is loaded several times per command
+var resolved = make(map[string]string)
+
+// Resolve returns the secret referred to by value, or value itself when it
+// is a plain (non-reference) value
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	if secret, ok := resolved[value]; ok {
+		return secret, nil
+	}
+
+	store, key, err := OpenRef(value)
+	if err != nil {
+		return "", err
+	}
+	secret, err := store.Get(key)
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", fmt.Errorf("secret %s not found in %s store", key, store.Name())
+	}
+
+	resolved[value] = secret
+	return secret, nil
+}
+
+// Refresh resolves value again, bypassing secrets resolved earlier in this process
+func Refresh(value string) (string, error) {
+	delete(resolved, value)
+	return Resolve(value)
+}
@@ -0,0 +1,55 @@
+package secret
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	t.Setenv(BackendEnvVar, "file")
+	t.Setenv(FileEnvVar, path)
+	t.Setenv(PassphraseEnvVar, "correct horse")
+	delete(stores, "file")
+
+	store, err := Open()
+	require.NoError(t, err)
+	assert.Equal(t, "file", store.Name())
+
+	require.NoError(t, store.Set("token", "secret-token"))
+	require.NoError(t, store.Set("wireguard/42", "private-key"))
+
+	value, err := Resolve(Ref(store, "token"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", value)
+
+	// The reference names its backend, whichever one is selected later
+	assert.Equal(t, "file:token", Ref(store, "token"))
+	t.Setenv(BackendEnvVar, "keyring")
+	value, err = Refresh(Ref(store, "token"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret-token", value)
+
+	value, err = Resolve("plain-token")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-token", value)
+
+	require.NoError(t, store.Delete("token"))
+	delete(resolved, Ref(store, "token"))
+	_, err = Resolve(Ref(store, "token"))
+	assert.EqualError(t, err, "secret token not found in file store")
+
+	backend, key, ok := ParseRef("keyring:wireguard/42")
+	assert.True(t, ok)
+	assert.Equal(t, "keyring", backend)
+	assert.Equal(t, "wireguard/42", key)
+	assert.False(t, IsRef("https://example.com"))
+
+	// A fresh store with the wrong passphrase must not decrypt the file
+	wrong := &fileStore{path: path, passphrase: "wrong"}
+	_, err = wrong.Get("wireguard/42")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package state
+
+import "fmt"
+
+// Merge plans the creation of the configs and mappings of doc that current
+// lacks, for importing. Configs are matched by name and region, and reused
+// when they have the same type; mappings that already exist are left alone.
+// A config whose name is taken by another one is created under a free name
+// ending in its region, and renamed in doc too. The returned notes say what
+// was reused, renamed or skipped.
+func Merge(doc, current *Document) (*Plan, []string) {
+	existing := make(map[configKey]Config)
+	taken := make(map[string]bool)
+	for _, c := range current.Configs {
+		key := keyOf(c)
+		if _, ok := existing[key]; !ok {
+			existing[key] = c
+		}
+		taken[c.Name] = true
+	}
+
+	plan := &Plan{}
+	var notes []string
+	for i := range doc.Configs {
+		c := doc.Configs[i]
+		have, ok := existing[keyOf(c)]
+		if !ok || have.Type != c.Type {
+			if taken[c.Name] {
+				name := freeName(c, taken)
+				notes = append(notes, fmt.Sprintf("config %s exists in another region or with another type, creating it as %s", c.Name, name))
+				c.Name = name
+				doc.Configs[i].Name = name
+			}
+			taken[c.Name] = true
+			c.ID = ""
+			plan.Actions = append(plan.Actions, Action{Op: Create, Config: bare(c)})
+			for j := range c.Mappings {
+				plan.Actions = append(plan.Actions, Action{Op: Create, Config: bare(c), Mapping: &c.Mappings[j]})
+			}
+			continue
+		}
+		notes = append(notes, fmt.Sprintf("config %s exists, using id %s", c.Name, have.ID))
+
+		mappings := make(map[string]bool)
+		for _, m := range have.Mappings {
+			mappings[m.Key()] = true
+		}
+		c.ID = have.ID
+		for j := range c.Mappings {
+			if mappings[c.Mappings[j].Key()] {
+				notes = append(notes, fmt.Sprintf("mapping %s [%s] skipped: exists", c.Mappings[j].Key(), c.Name))
+				continue
+			}
+			plan.Actions = append(plan.Actions, Action{Op: Create, Config: bare(c), Mapping: &c.Mappings[j]})
+		}
+	}
+	return plan, notes
+}
+
+// configKey identifies a config by name and region
+type configKey struct {
+	name, region string
+}
+
+func keyOf(c Config) configKey {
+	return configKey{c.Name, c.Region}
+}
+
+// freeName returns the name of c suffixed with its region, then also with a
+// number, whichever is not taken yet
+func freeName(c Config, taken map[string]bool) string {
+	name := c.Name + "-" + c.Region
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s-%s-%d", c.Name, c.Region, i)
+	}
+	return name
+}
@@ -0,0 +1,240 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+
+	"portmap.io/client/internal/api"
+)
+
+// Operations of a plan action
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// Action is one change of a plan. Mapping is nil for config actions.
+type Action struct {
+	Op      string   `json:"op"`
+	Config  Config   `json:"config"`
+	Mapping *Mapping `json:"mapping,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// Plan is the list of changes that turn the current state into the desired one,
+// ordered so configs exist before their mappings and outlive them
+type Plan struct {
+	Actions []Action `json:"actions"`
+}
+
+// ClientFunc returns the client serving region
+type ClientFunc func(region string) api.Client
+
+// String renders a in plan notation: + create, ~ update, - delete
+func (a Action) String() string {
+	symbol := map[string]string{Create: "+", Update: "~", Delete: "-"}[a.Op]
+	return symbol + " " + a.describe()
+}
+
+func (a Action) describe() string {
+	if a.Mapping == nil {
+		s := fmt.Sprintf("config %s (%s, %s)", a.Config.Name, a.Config.Type, a.Config.Region)
+		if a.Op == Delete && len(a.Config.Mappings) > 0 {
+			s += fmt.Sprintf(" with %d mapping(s)", len(a.Config.Mappings))
+		}
+		return s
+	}
+
+	s := fmt.Sprintf("mapping %s", a.Mapping.Key())
+	if a.Op == Create {
+		s += " -> " + a.Mapping.PortTo
+	}
+	s += fmt.Sprintf(" [%s]", a.Config.Name)
+	if len(a.Changes) > 0 {
+		s += ": " + strings.Join(a.Changes, ", ")
+	}
+	return s
+}
+
+// Summary counts the actions of p by operation
+func (p *Plan) Summary() string {
+	counts := make(map[string]int)
+	for _, a := range p.Actions {
+		counts[a.Op]++
+	}
+	return fmt.Sprintf("Plan: %d to create, %d to update, %d to delete.", counts[Create], counts[Update], counts[Delete])
+}
+
+// Deletes returns the delete actions of p
+func (p *Plan) Deletes() []Action {
+	var deletes []Action
+	for _, a := range p.Actions {
+		if a.Op == Delete {
+			deletes = append(deletes, a)
+		}
+	}
+	return deletes
+}
+
+// Diff plans the changes turning current into desired. Configs are matched by
+// name and mappings by protocol, hostname and port within their config. Only
+// with prune are configs and mappings missing from desired deleted.
+func Diff(desired, current *Document, prune bool) (*Plan, error) {
+	existing := make(map[string]Config)
+	for _, c := range current.Configs {
+		if _, ok := existing[c.Name]; !ok {
+			existing[c.Name] = c
+		}
+	}
+
+	var creates, updates, deletes []Action
+	declared := make(map[string]bool)
+	for _, want := range desired.Configs {
+		declared[want.Name] = true
+		have, ok := existing[want.Name]
+		if !ok {
+			// IDs in the file belong to whatever account it was written from
+			want.ID = ""
+			creates = append(creates, Action{Op: Create, Config: bare(want)})
+			for i := range want.Mappings {
+				creates = append(creates, Action{Op: Create, Config: bare(want), Mapping: &want.Mappings[i]})
+			}
+			continue
+		}
+
+		// The API has no way to change a config in place
+		if want.Type != have.Type || want.Region != have.Region || (want.Type == "OpenVPN" && want.OpenVPNProto != have.OpenVPNProto) {
+			return nil, fmt.Errorf("config %s exists as %s in region %s and can't be changed, delete it or use another name", have.Name, have.Type, have.Region)
+		}
+		want.ID = have.ID
+		config := bare(want)
+
+		mappings := make(map[string]Mapping)
+		for _, m := range have.Mappings {
+			mappings[m.Key()] = m
+		}
+		wanted := make(map[string]bool)
+		for i := range want.Mappings {
+			m := want.Mappings[i]
+			wanted[m.Key()] = true
+			old, ok := mappings[m.Key()]
+			if !ok {
+				creates = append(creates, Action{Op: Create, Config: config, Mapping: &m})
+				continue
+			}
+			if changes := compare(old, m); len(changes) > 0 {
+				m.ID = old.ID
+				updates = append(updates, Action{Op: Update, Config: config, Mapping: &m, Changes: changes})
+			}
+		}
+
+		if prune {
+			for i := range have.Mappings {
+				if !wanted[have.Mappings[i].Key()] {
+					deletes = append(deletes, Action{Op: Delete, Config: config, Mapping: &have.Mappings[i]})
+				}
+			}
+		}
+	}
+
+	// Deleting a config takes its mappings with it
+	if prune {
+		for _, have := range current.Configs {
+			if !declared[have.Name] {
+				deletes = append(deletes, Action{Op: Delete, Config: have})
+			}
+		}
+	}
+
+	plan := &Plan{}
+	plan.Actions = append(plan.Actions, creates...)
+	plan.Actions = append(plan.Actions, updates...)
+	plan.Actions = append(plan.Actions, deletes...)
+	return plan, nil
+}
+
+// bare returns c without its mappings, for actions that concern the config alone
+func bare(c Config) Config {
+	c.Mappings = nil
+	return c
+}
+
+// compare lists the settings that differ between the current and desired mapping,
+// ignoring the ones the protocol doesn't use
+func compare(have, want Mapping) []string {
+	var changes []string
+	diff := func(field string, old, new interface{}) {
+		if old != new {
+			changes = append(changes, fmt.Sprintf("%s %v -> %v", field, old, new))
+		}
+	}
+
+	diff("port_to", have.PortTo, want.PortTo)
+	diff("allowed_ip", have.AllowedIP, want.AllowedIP)
+	if want.Protocol == "http" || want.Protocol == "https" {
+		diff("hostheader", have.HostHeader, want.HostHeader)
+		diff("use_custom_domain", have.UseCustomDomain, want.UseCustomDomain)
+		diff("websockets", have.WebSockets, want.WebSockets)
+		if want.WebSockets {
+			diff("ws_timeout", have.WSTimeout, want.WSTimeout)
+		}
+	}
+	if want.Protocol == "https" {
+		diff("proxy_to_http", have.ProxyToHTTP, want.ProxyToHTTP)
+	}
+	return changes
+}
+
+// Apply carries out the plan, calling done after each action with the config ID
+// filled in for created configs. It stops at the first failure.
+func (p *Plan) Apply(clientFor ClientFunc, done func(Action)) error {
+	created := make(map[string]string)
+	for _, a := range p.Actions {
+		client := clientFor(a.Config.Region)
+		configID := a.Config.ID
+		if configID == "" {
+			configID = created[a.Config.Name]
+		}
+
+		var err error
+		switch {
+		case a.Mapping == nil && a.Op == Create:
+			var result interface{}
+			result, err = client.CreateConfig(a.Config.Request())
+			if err == nil {
+				configID = createdID(result)
+				if configID == "" {
+					err = fmt.Errorf("no config ID in response")
+				}
+				created[a.Config.Name] = configID
+			}
+		case a.Mapping == nil && a.Op == Delete:
+			err = client.DeleteConfig(configID)
+		case a.Op == Create:
+			_, err = client.CreateMapping(a.Mapping.Request(configID))
+		case a.Op == Update:
+			_, err = client.UpdateMapping(a.Mapping.ID, a.Mapping.Request(configID))
+		case a.Op == Delete:
+			err = client.DeleteMapping(a.Mapping.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s: %w", a.Op, a.describe(), err)
+		}
+
+		if done != nil {
+			a.Config.ID = configID
+			done(a)
+		}
+	}
+	return nil
+}
+
+func createdID(result interface{}) string {
+	if response, ok := result.(map[string]interface{}); ok {
+		if data, ok := response["data"].(map[string]interface{}); ok {
+			return str(data["id"])
+		}
+	}
+	return ""
+}
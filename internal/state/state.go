@@ -0,0 +1,292 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/validation"
+)
+
+// Version is the document format written by this client
+const Version = 1
+
+// Document describes configs and the mappings bound to them
+type Document struct {
+	Version int      `yaml:"version" json:"version"`
+	Configs []Config `yaml:"configs" json:"configs"`
+}
+
+// Config is a configuration with its mappings. ID is only set for configs
+// read from the API.
+type Config struct {
+	ID           string    `yaml:"id,omitempty" json:"id,omitempty"`
+	Name         string    `yaml:"name" json:"name"`
+	Type         string    `yaml:"type" json:"type"`
+	Region       string    `yaml:"region,omitempty" json:"region,omitempty"`
+	OpenVPNProto string    `yaml:"openvpn_proto,omitempty" json:"openvpn_proto,omitempty"`
+	Comment      string    `yaml:"comment,omitempty" json:"comment,omitempty"`
+	Mappings     []Mapping `yaml:"mappings,omitempty" json:"mappings,omitempty"`
+}
+
+// Mapping is a mapping rule. ID is only set for mappings read from the API.
+type Mapping struct {
+	ID              string `yaml:"id,omitempty" json:"id,omitempty"`
+	Hostname        string `yaml:"hostname" json:"hostname"`
+	Protocol        string `yaml:"protocol" json:"protocol"`
+	PortFrom        string `yaml:"port_from" json:"port_from"`
+	PortTo          string `yaml:"port_to" json:"port_to"`
+	HostHeader      string `yaml:"hostheader,omitempty" json:"hostheader,omitempty"`
+	AllowedIP       string `yaml:"allowed_ip,omitempty" json:"allowed_ip,omitempty"`
+	UseCustomDomain bool   `yaml:"use_custom_domain,omitempty" json:"use_custom_domain,omitempty"`
+	WebSockets      bool   `yaml:"websockets,omitempty" json:"websockets,omitempty"`
+	WSTimeout       int    `yaml:"ws_timeout,omitempty" json:"ws_timeout,omitempty"`
+	ProxyToHTTP     bool   `yaml:"proxy_to_http,omitempty" json:"proxy_to_http,omitempty"`
+}
+
+// Key identifies a mapping within its config
+func (m Mapping) Key() string {
+	return fmt.Sprintf("%s://%s:%s", m.Protocol, m.Hostname, m.PortFrom)
+}
+
+// Request returns the API request creating or updating m under configID
+func (m Mapping) Request(configID string) api.MappingRequest {
+	return api.MappingRequest{
+		Hostname:        m.Hostname,
+		PortFrom:        m.PortFrom,
+		Protocol:        m.Protocol,
+		PortTo:          m.PortTo,
+		ConfigID:        configID,
+		HostHeader:      m.HostHeader,
+		UseCustomDomain: m.UseCustomDomain,
+		AllowedIP:       m.AllowedIP,
+		WebSockets:      m.WebSockets,
+		WSTimeout:       m.WSTimeout,
+		ProxyToHTTP:     m.ProxyToHTTP,
+	}
+}
+
+// Request returns the API request creating c
+func (c Config) Request() api.ConfigRequest {
+	return api.ConfigRequest{
+		Name:         c.Name,
+		Type:         c.Type,
+		OpenvpnProto: c.OpenVPNProto,
+		Region:       c.Region,
+		Comment:      c.Comment,
+	}
+}
+
+// Load reads a YAML or JSON document
+func Load(r io.Reader) (*Document, error) {
+	var doc Document
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("document is empty")
+		}
+		return nil, fmt.Errorf("failed to parse document: %w", err)
+	}
+
+	if doc.Version == 0 {
+		return nil, fmt.Errorf("document has no version, expected version: %d", Version)
+	}
+	if doc.Version > Version {
+		return nil, fmt.Errorf("document version %d is newer than supported version %d, upgrade the client", doc.Version, Version)
+	}
+
+	for i := range doc.Configs {
+		if doc.Configs[i].Region == "" {
+			doc.Configs[i].Region = "default"
+		}
+	}
+	return &doc, nil
+}
+
+// LoadFile reads a document from file, or from stdin when file is "-"
+func LoadFile(file string) (*Document, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	doc, err := Load(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", file, err)
+	}
+	return doc, nil
+}
+
+// Encode writes doc as "yaml" or "json"
+func Encode(w io.Writer, doc *Document, format string) error {
+	switch format {
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	default:
+		return fmt.Errorf("invalid document format: %s (supported: yaml, json)", format)
+	}
+}
+
+// Spec returns the fields of c to validate
+func (c Config) Spec() validation.ConfigSpec {
+	return validation.ConfigSpec{
+		Name:         c.Name,
+		Type:         c.Type,
+		Region:       c.Region,
+		OpenVPNProto: c.OpenVPNProto,
+		Comment:      c.Comment,
+	}
+}
+
+// Spec returns the fields of m to validate, within a config of configType
+func (m Mapping) Spec(configType string) validation.MappingSpec {
+	return validation.MappingSpec{
+		ConfigType: configType,
+		Hostname:   m.Hostname,
+		Protocol:   m.Protocol,
+		PortFrom:   m.PortFrom,
+		PortTo:     m.PortTo,
+		HostHeader: m.HostHeader,
+		AllowedIP:  m.AllowedIP,
+		WebSockets: m.WebSockets,
+		WSTimeout:  m.WSTimeout,
+	}
+}
+
+// Validate checks every field of doc with the validation rules of the create
+// commands, and returns all the problems found as validation.Errors
+func Validate(doc *Document) error {
+	var problems validation.Errors
+	names := make(map[string]bool)
+	for i, c := range doc.Configs {
+		path := fmt.Sprintf("configs[%d]", i)
+		problems = append(problems, c.Spec().Validate().Within(path)...)
+		if c.Name != "" && names[c.Name] {
+			problems.Add(path+".name", fmt.Sprintf("config %s is declared more than once", c.Name))
+		}
+		names[c.Name] = true
+
+		keys := make(map[string]bool)
+		for j, m := range c.Mappings {
+			mappingPath := fmt.Sprintf("%s.mappings[%d]", path, j)
+			problems = append(problems, m.Spec(c.Type).Validate().Within(mappingPath)...)
+			if keys[m.Key()] {
+				problems.Add(mappingPath, fmt.Sprintf("mapping %s is declared more than once", m.Key()))
+			}
+			keys[m.Key()] = true
+		}
+	}
+	return problems.Err()
+}
+
+// Fetch reads every config and mapping of the account into a document
+func Fetch(client api.Client) (*Document, error) {
+	configs, err := client.ListConfigs(map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list configurations: %w", err)
+	}
+	mappings, err := client.ListMappings(map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mappings: %w", err)
+	}
+
+	doc := &Document{Version: Version}
+	index := make(map[string]int)
+	for _, item := range items(configs) {
+		c := Config{
+			ID:           str(item["id"]),
+			Name:         str(item["name"]),
+			Type:         str(item["type"]),
+			Region:       str(item["region"]),
+			OpenVPNProto: str(item["openvpn_proto"]),
+			Comment:      str(item["comment"]),
+		}
+		if c.Region == "" {
+			c.Region = "default"
+		}
+		if c.OpenVPNProto == "" && c.Type == "OpenVPN" {
+			c.OpenVPNProto = str(item["proto"])
+		}
+		index[c.ID] = len(doc.Configs)
+		doc.Configs = append(doc.Configs, c)
+	}
+
+	for _, item := range items(mappings) {
+		configID := str(item["config_id"])
+		if config, ok := item["config"].(map[string]interface{}); ok {
+			configID = str(config["id"])
+		}
+		i, ok := index[configID]
+		if !ok {
+			continue
+		}
+
+		m := Mapping{
+			ID:         str(item["id"]),
+			Hostname:   str(item["hostname"]),
+			Protocol:   str(item["protocol"]),
+			PortFrom:   str(item["port_from"]),
+			PortTo:     str(item["port_to"]),
+			HostHeader: str(item["hostheader"]),
+			AllowedIP:  str(item["allowed_ip"]),
+		}
+		m.UseCustomDomain, _ = item["use_custom_domain"].(bool)
+		m.WebSockets, _ = item["websockets"].(bool)
+		m.ProxyToHTTP, _ = item["proxy_to_http"].(bool)
+		if timeout, ok := item["ws_timeout"].(float64); ok {
+			m.WSTimeout = int(timeout)
+		}
+		doc.Configs[i].Mappings = append(doc.Configs[i].Mappings, m)
+	}
+
+	return doc, nil
+}
+
+// items returns the objects of a list response
+func items(response interface{}) []map[string]interface{} {
+	var list []map[string]interface{}
+	if wrapper, ok := response.(map[string]interface{}); ok {
+		if data, ok := wrapper["data"].([]interface{}); ok {
+			for _, entry := range data {
+				if item, ok := entry.(map[string]interface{}); ok {
+					list = append(list, item)
+				}
+			}
+		}
+	}
+	return list
+}
+
+// str formats an API value, keeping numbers free of exponents and nulls empty
+func str(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,180 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/validation"
+)
+
+const document = `
+version: 1
+configs:
+  - name: office
+    type: WireGuard
+    region: fra1
+    mappings:
+      - hostname: office.portmap.io
+        protocol: https
+        port_from: 443
+        port_to: 8080
+        proxy_to_http: true
+      - hostname: office.portmap.io
+        protocol: tcp
+        port_from: 2222
+        port_to: 22
+  - name: lab
+    type: SSH
+    mappings:
+      - hostname: lab.portmap.io
+        protocol: tcp
+        port_from: 3000
+        port_to: 3000
+`
+
+func TestLoad(t *testing.T) {
+	doc, err := Load(strings.NewReader(document))
+	require.NoError(t, err)
+	require.Len(t, doc.Configs, 2)
+	assert.Equal(t, "443", doc.Configs[0].Mappings[0].PortFrom)
+	assert.Equal(t, "default", doc.Configs[1].Region)
+	assert.NoError(t, Validate(doc))
+
+	_, err = Load(strings.NewReader("configs: []"))
+	assert.ErrorContains(t, err, "no version")
+
+	_, err = Load(strings.NewReader("version: 1\nconfigs:\n  - name: a\n    kind: SSH"))
+	assert.ErrorContains(t, err, "kind")
+
+	doc.Configs[1].Mappings[0].PortFrom = "80"
+	doc.Configs[1].Mappings[0].PortTo = "http"
+	err = Validate(doc)
+	var problems validation.Errors
+	require.ErrorAs(t, err, &problems)
+	assert.Equal(t, validation.Errors{
+		{Field: "configs[1].mappings[0].port_from", Message: "Port 80 is only allowed for http protocol"},
+		{Field: "configs[1].mappings[0].port_to", Message: "Port must be a number"},
+	}, problems)
+}
+
+func TestDiff(t *testing.T) {
+	desired, err := Load(strings.NewReader(document))
+	require.NoError(t, err)
+
+	current := &Document{Version: Version, Configs: []Config{
+		{ID: "1", Name: "office", Type: "WireGuard", Region: "fra1", Mappings: []Mapping{
+			{ID: "10", Hostname: "office.portmap.io", Protocol: "https", PortFrom: "443", PortTo: "80", ProxyToHTTP: true},
+			{ID: "11", Hostname: "office.portmap.io", Protocol: "udp", PortFrom: "5000", PortTo: "5000"},
+		}},
+		{ID: "2", Name: "old", Type: "SSH", Region: "default"},
+	}}
+
+	plan, err := Diff(desired, current, false)
+	require.NoError(t, err)
+	var lines []string
+	for _, a := range plan.Actions {
+		lines = append(lines, a.String())
+	}
+	assert.Equal(t, []string{
+		"+ mapping tcp://office.portmap.io:2222 -> 22 [office]",
+		"+ config lab (SSH, default)",
+		"+ mapping tcp://lab.portmap.io:3000 -> 3000 [lab]",
+		"~ mapping https://office.portmap.io:443 [office]: port_to 80 -> 8080",
+	}, lines)
+
+	plan, err = Diff(desired, current, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Plan: 3 to create, 1 to update, 2 to delete.", plan.Summary())
+	assert.Equal(t, "- mapping udp://office.portmap.io:5000 [office]", plan.Actions[4].String())
+	assert.Equal(t, "- config old (SSH, default)", plan.Actions[5].String())
+	assert.Equal(t, plan.Actions[4:], plan.Deletes())
+
+	current.Configs[0].Region = "nyc1"
+	_, err = Diff(desired, current, false)
+	assert.ErrorContains(t, err, "can't be changed")
+}
+
+// fakeClient records the calls Apply makes; unused methods are left to the nil interface
+type fakeClient struct {
+	api.Client
+	calls []string
+}
+
+func (f *fakeClient) CreateConfig(req api.ConfigRequest) (interface{}, error) {
+	f.calls = append(f.calls, "create config "+req.Name)
+	return map[string]interface{}{"data": map[string]interface{}{"id": float64(7)}}, nil
+}
+
+func (f *fakeClient) CreateMapping(req api.MappingRequest) (interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("create mapping %s:%s on %s", req.Hostname, req.PortFrom, req.ConfigID))
+	return nil, nil
+}
+
+func (f *fakeClient) UpdateMapping(id string, req api.MappingRequest) (interface{}, error) {
+	f.calls = append(f.calls, fmt.Sprintf("update mapping %s to %s", id, req.PortTo))
+	return nil, nil
+}
+
+func TestApply(t *testing.T) {
+	desired, err := Load(strings.NewReader(document))
+	require.NoError(t, err)
+	current := &Document{Version: Version, Configs: []Config{
+		{ID: "1", Name: "office", Type: "WireGuard", Region: "fra1", Mappings: []Mapping{
+			{ID: "10", Hostname: "office.portmap.io", Protocol: "https", PortFrom: "443", PortTo: "80", ProxyToHTTP: true},
+		}},
+	}}
+
+	plan, err := Diff(desired, current, false)
+	require.NoError(t, err)
+
+	client := &fakeClient{}
+	var regions []string
+	err = plan.Apply(func(region string) api.Client {
+		regions = append(regions, region)
+		return client
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"create mapping office.portmap.io:2222 on 1",
+		"create config lab",
+		"create mapping lab.portmap.io:3000 on 7",
+		"update mapping 10 to 8080",
+	}, client.calls)
+	assert.Equal(t, []string{"fra1", "default", "default", "fra1"}, regions)
+}
+
+func TestMerge(t *testing.T) {
+	doc, err := Load(strings.NewReader(document))
+	require.NoError(t, err)
+	doc.Configs[1].ID = "99"
+
+	current := &Document{Version: Version, Configs: []Config{
+		{ID: "1", Name: "office", Type: "WireGuard", Region: "fra1", Mappings: []Mapping{
+			{ID: "10", Hostname: "office.portmap.io", Protocol: "https", PortFrom: "443", PortTo: "80"},
+		}},
+	}}
+
+	plan, notes := Merge(doc, current)
+	assert.Equal(t, []string{
+		"config office exists, using id 1",
+		"mapping https://office.portmap.io:443 [office] skipped: exists",
+	}, notes)
+	require.Len(t, plan.Actions, 3)
+	assert.Equal(t, "1", plan.Actions[0].Config.ID)
+	// The ID from the document belongs to the source account
+	assert.Equal(t, "", plan.Actions[1].Config.ID)
+	assert.Equal(t, "+ config lab (SSH, default)", plan.Actions[1].String())
+
+	// Cloning into a region where the name is taken creates a renamed copy
+	current.Configs[0].Region = "nyc1"
+	plan, notes = Merge(doc, current)
+	assert.Equal(t, []string{"config office exists in another region or with another type, creating it as office-fra1"}, notes)
+	require.Len(t, plan.Actions, 5)
+	assert.Equal(t, "office-fra1", plan.Actions[0].Config.Name)
+	assert.Equal(t, "office-fra1", plan.Actions[1].Config.Name)
+	assert.Equal(t, "office-fra1", doc.Configs[0].Name)
+}
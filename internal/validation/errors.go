@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError is what is wrong with one field of a request, named by its flag
+// on the command line or its path in a file
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Errors are all the problems found with a request, so they can be fixed in one go
+type Errors []FieldError
+
+// Add records a problem with field
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Within returns the problems with their fields under path, such as
+// configs[0].mappings[1]
+func (e Errors) Within(path string) Errors {
+	within := make(Errors, len(e))
+	for i, fe := range e {
+		within[i] = FieldError{Field: path + "." + fe.Field, Message: fe.Message}
+	}
+	return within
+}
+
+// Rename returns the problems with their fields renamed through names, such
+// as the fields of a spec to the flags setting them
+func (e Errors) Rename(names map[string]string) Errors {
+	renamed := make(Errors, len(e))
+	for i, fe := range e {
+		if name, ok := names[fe.Field]; ok {
+			fe.Field = name
+		}
+		renamed[i] = fe
+	}
+	return renamed
+}
+
+// Invalid returns the problems other than missing fields, for when those
+// are asked for next
+func (e Errors) Invalid() Errors {
+	var invalid Errors
+	for _, fe := range e {
+		if fe.Message != Required {
+			invalid = append(invalid, fe)
+		}
+	}
+	return invalid
+}
+
+// Err returns the problems as an error, or nil when there are none
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return "invalid input: " + e[0].Error()
+	}
+	lines := []string{fmt.Sprintf("%d invalid inputs:", len(e))}
+	for _, fe := range e {
+		lines = append(lines, "  "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
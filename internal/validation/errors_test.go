@@ -0,0 +1,30 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	var problems Errors
+	assert.NoError(t, problems.Err())
+
+	problems.Add("--hostname", "required")
+	assert.EqualError(t, problems.Err(), "invalid input: --hostname: required")
+
+	problems.Add("--port-to", "Port must be in range [1-65535]")
+	assert.EqualError(t, problems.Err(), "2 invalid inputs:\n  --hostname: required\n  --port-to: Port must be in range [1-65535]")
+}
+
+func TestErrorsFields(t *testing.T) {
+	problems := Errors{{Field: "hostname", Message: Required}, {Field: "port_to", Message: "Port must be a number"}}
+
+	assert.Equal(t, Errors{{Field: "port_to", Message: "Port must be a number"}}, problems.Invalid())
+	assert.Equal(t, "configs[0].mappings[1].hostname", problems.Within("configs[0].mappings[1]")[0].Field)
+
+	renamed := problems.Rename(map[string]string{"port_to": "--port-to"})
+	assert.Equal(t, "hostname", renamed[0].Field)
+	assert.Equal(t, "--port-to", renamed[1].Field)
+	assert.Equal(t, "port_to", problems[1].Field)
+}
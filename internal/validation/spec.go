@@ -0,0 +1,109 @@
+package validation
+
+// Required is the message of a field that is missing
+const Required = "required"
+
+// MappingSpec is a mapping rule as given to mapping create or in an apply
+// file. ConfigType is the type of the config it belongs to, when known.
+type MappingSpec struct {
+	ConfigType string
+	Hostname   string
+	Protocol   string
+	PortFrom   string
+	PortTo     string
+	HostHeader string
+	AllowedIP  string
+	WebSockets bool
+	WSTimeout  int
+}
+
+// Validate checks every field of s, and the rules between them. Fields are
+// named as in the API; a rule between fields is only checked once both are
+// valid, so each problem is reported once.
+func (s MappingSpec) Validate() Errors {
+	var problems Errors
+	check := func(field, value string, validate func(string) (bool, string)) bool {
+		if value == "" {
+			problems.Add(field, Required)
+			return false
+		}
+		if valid, msg := validate(value); !valid {
+			problems.Add(field, msg)
+			return false
+		}
+		return true
+	}
+
+	check("hostname", s.Hostname, IsValidHostname)
+	protocol := check("protocol", s.Protocol, IsValidProtocol)
+	if check("port_from", s.PortFrom, IsValidPortNumber) && protocol {
+		// Without a config yet, only the rules of the protocol apply
+		configType := s.ConfigType
+		if configType == "" {
+			configType = "WireGuard"
+		}
+		if valid, msg := IsValidPort(s.PortFrom, s.Protocol, configType); !valid {
+			problems.Add("port_from", msg)
+		}
+	}
+	check("port_to", s.PortTo, IsValidPortNumber)
+
+	web := s.Protocol == "http" || s.Protocol == "https"
+	if valid, msg := IsValidHostHeader(s.HostHeader); !valid {
+		problems.Add("hostheader", msg)
+	} else if s.HostHeader != "" && protocol && !web {
+		problems.Add("hostheader", "Host header is only used by http and https mappings")
+	}
+	if valid, msg := IsValidCIDR(s.AllowedIP); !valid {
+		problems.Add("allowed_ip", msg)
+	}
+	if s.WebSockets && protocol && !web {
+		problems.Add("websockets", "WebSockets are only supported by http and https mappings")
+	}
+	if valid, msg := IsValidWSTimeout(s.WSTimeout); !valid {
+		problems.Add("ws_timeout", msg)
+	}
+	return problems
+}
+
+// ConfigSpec is a configuration as given to config create or in an apply
+// file. An empty Region takes the default one.
+type ConfigSpec struct {
+	Name         string
+	Type         string
+	Region       string
+	OpenVPNProto string
+	Comment      string
+}
+
+// Validate checks every field of s, including the OpenVPN protocol that
+// OpenVPN configurations need
+func (s ConfigSpec) Validate() Errors {
+	var problems Errors
+	if s.Name == "" {
+		problems.Add("name", Required)
+	} else if valid, msg := IsValidName(s.Name); !valid {
+		problems.Add("name", msg)
+	}
+	if s.Type == "" {
+		problems.Add("type", Required)
+	} else if valid, msg := IsValidConfigType(s.Type); !valid {
+		problems.Add("type", msg)
+	}
+	if s.Region != "" {
+		if valid, msg := IsValidRegion(s.Region); !valid {
+			problems.Add("region", msg)
+		}
+	}
+	if s.Type == "OpenVPN" {
+		if s.OpenVPNProto == "" {
+			problems.Add("openvpn_proto", Required)
+		} else if valid, msg := IsValidOpenVPNProto(s.OpenVPNProto); !valid {
+			problems.Add("openvpn_proto", msg)
+		}
+	}
+	if valid, msg := IsValidComment(s.Comment); !valid {
+		problems.Add("comment", msg)
+	}
+	return problems
+}
@@ -0,0 +1,63 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"portmap.io/client/internal/regions"
+)
+
+func TestMappingSpec(t *testing.T) {
+	valid := MappingSpec{ConfigType: "WireGuard", Hostname: "app.portmap.io", Protocol: "https", PortFrom: "443", PortTo: "8080"}
+	assert.Empty(t, valid.Validate())
+
+	// Every field is reported, not only the first one
+	spec := MappingSpec{Hostname: "app.example.com", Protocol: "ftp", PortTo: "0", AllowedIP: "10.0.0.1", WSTimeout: -1}
+	assert.Equal(t, Errors{
+		{Field: "hostname", Message: "Hostname must end with .portmap.io or .portmap.host"},
+		{Field: "protocol", Message: "Protocol must be one of: tcp, udp, http, https"},
+		{Field: "port_from", Message: Required},
+		{Field: "port_to", Message: "Port must be in range [1-65535]"},
+		{Field: "allowed_ip", Message: "Invalid CIDR format (e.g., 192.168.1.0/24)"},
+		{Field: "ws_timeout", Message: "WebSocket timeout must be non-negative"},
+	}, spec.Validate())
+
+	tests := []struct {
+		name    string
+		spec    MappingSpec
+		field   string
+		message string
+	}{
+		{"443 needs https", MappingSpec{Protocol: "http", PortFrom: "443"}, "port_from", "Port 443 is only allowed for https protocol"},
+		{"80 needs a VPN", MappingSpec{ConfigType: "SSH", Protocol: "http", PortFrom: "80"}, "port_from", "Port 80 is only allowed for OpenVPN and WireGuard configurations"},
+		{"host header needs http", MappingSpec{Protocol: "tcp", PortFrom: "2000", HostHeader: "example.com"}, "hostheader", "Host header is only used by http and https mappings"},
+		{"websockets need http", MappingSpec{Protocol: "udp", PortFrom: "2000", WebSockets: true}, "websockets", "WebSockets are only supported by http and https mappings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Contains(t, tt.spec.Validate(), FieldError{Field: tt.field, Message: tt.message})
+		})
+	}
+
+	// Rules between fields wait for both to be valid
+	assert.Equal(t, Errors{
+		{Field: "hostname", Message: Required},
+		{Field: "protocol", Message: Required},
+		{Field: "port_to", Message: Required},
+	}, MappingSpec{PortFrom: "443", HostHeader: "example.com"}.Validate())
+}
+
+func TestConfigSpec(t *testing.T) {
+	assert.Empty(t, ConfigSpec{Name: "office", Type: "WireGuard"}.Validate())
+	assert.Empty(t, ConfigSpec{Name: "vpn", Type: "OpenVPN", OpenVPNProto: "udp", Region: "default"}.Validate())
+
+	assert.Equal(t, Errors{
+		{Field: "name", Message: Required},
+		{Field: "openvpn_proto", Message: Required},
+	}, ConfigSpec{Type: "OpenVPN"}.Validate())
+	assert.Equal(t, Errors{
+		{Field: "type", Message: "Type must be one of: OpenVPN, SSH, WireGuard"},
+		{Field: "region", Message: "Region must be one of: " + strings.Join(regions.Names(), ", ")},
+	}, ConfigSpec{Name: "office", Type: "PPTP", Region: "mars"}.Validate())
+}
@@ -5,6 +5,9 @@ import (
 	"net"
 	"regexp"
 	"strings"
+
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/regions"
 )
 
 const (
@@ -45,7 +48,7 @@ func IsValidConfigID(configID string, configs map[string]interface{}) bool {
 	if data, ok := configs["data"].([]interface{}); ok {
 		for _, conf := range data {
 			if config, ok := conf.(map[string]interface{}); ok {
-				if fmt.Sprintf("%v", config["id"]) == configID {
+				if api.FormatID(config["id"]) == configID {
 					return true
 				}
 			}
@@ -186,16 +189,8 @@ func IsValidRegion(region string) (bool, string) {
 	if valid, msg := validateLength(region, MaxInputLength, "Region"); !valid {
 		return false, msg
 	}
-	validRegions := map[string]bool{
-		"default": true,
-		"nyc1":    true,
-		"fra1":    true,
-		"blr1":    true,
-		"sin1":    true,
-	}
-
-	if _, ok := validRegions[region]; !ok {
-		return false, "Region must be one of: default, nyc1, fra1, blr1, sin1"
+	if _, ok := regions.Lookup(region); !ok {
+		return false, "Region must be one of: " + strings.Join(regions.Names(), ", ")
 	}
 	return true, ""
 }
@@ -1,10 +1,12 @@
 package validation
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValidations(t *testing.T) {
@@ -316,3 +318,10 @@ func testIDValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestIsValidConfigID(t *testing.T) {
+	var configs map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"data": [{"id": 12345678}]}`), &configs))
+	assert.True(t, IsValidConfigID("12345678", configs))
+	assert.False(t, IsValidConfigID("1234567", configs))
+}
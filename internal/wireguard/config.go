@@ -1,19 +1,67 @@
 package wireguard
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"strings"
 
 	"gopkg.in/ini.v1"
 	"portmap.io/client/internal/config"
+	"portmap.io/client/internal/secret"
 )
 
+// ConfigEnvVar holds a WireGuard config injected as a secret, either raw or base64 encoded
+const ConfigEnvVar = "PORTMAP_WG_CONFIG"
+
+// ParseConfig parses a WireGuard config file at path
 func ParseConfig(path string) (*config.WireguardConfig, string, error) {
 	cfg, err := ini.Load(path)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to load config: %v", err)
 	}
+	return parseConfig(cfg)
+}
+
+// ParseConfigReader parses a WireGuard config from r, e.g. stdin or an injected secret
+func ParseConfigReader(r io.Reader) (*config.WireguardConfig, string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read config: %v", err)
+	}
+
+	cfg, err := ini.Load(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load config: %v", err)
+	}
+	return parseConfig(cfg)
+}
+
+// DecodeConfig returns the config text held in value, which may be either the
+// raw config or its base64 encoding
+func DecodeConfig(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, fmt.Errorf("config is empty")
+	}
+	if strings.HasPrefix(value, "[") {
+		return []byte(value), nil
+	}
+
+	// Secrets are often wrapped across lines when encoded, so drop whitespace first
+	compact := strings.Join(strings.Fields(value), "")
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding} {
+		if data, err := enc.DecodeString(compact); err == nil {
+			if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+				return trimmed, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("config is neither a WireGuard config nor base64 encoded one")
+}
 
+func parseConfig(cfg *ini.File) (*config.WireguardConfig, string, error) {
 	var config config.WireguardConfig
 
 	// Get config_id from portmap section
@@ -54,3 +102,63 @@ func ParseConfig(path string) (*config.WireguardConfig, string, error) {
 
 	return &config, configID, nil
 }
+
+// SetPrivateKey replaces the [Interface] PrivateKey of a WireGuard config text,
+// adding it when missing, and returns the new text along with the old key.
+// All other sections, including [portmap], are kept as they are.
+func SetPrivateKey(content, key string) (string, string) {
+	lines := strings.Split(content, "\n")
+	section := ""
+	oldKey := ""
+	interfaceLine := -1
+	replaced := false
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.Trim(trimmed, "[]")
+			if section == "Interface" {
+				interfaceLine = i
+			}
+			continue
+		}
+
+		name, value, found := strings.Cut(trimmed, "=")
+		if section == "Interface" && found && strings.TrimSpace(name) == "PrivateKey" {
+			oldKey = strings.TrimSpace(value)
+			lines[i] = "PrivateKey = " + key
+			replaced = true
+		}
+	}
+
+	if !replaced {
+		if interfaceLine == -1 {
+			lines = append([]string{"[Interface]", "PrivateKey = " + key, ""}, lines...)
+		} else {
+			lines = append(lines[:interfaceLine+1], append([]string{"PrivateKey = " + key}, lines[interfaceLine+1:]...)...)
+		}
+	}
+
+	return strings.Join(lines, "\n"), oldKey
+}
+
+// StorePrivateKey moves the plaintext private key of a WireGuard config text
+// into the secret store and returns the text with a reference in its place.
+// Configs that already use a reference are returned unchanged.
+func StorePrivateKey(content, configID string) (string, error) {
+	_, privateKey := SetPrivateKey(content, "")
+	if privateKey == "" || secret.IsRef(privateKey) {
+		return content, nil
+	}
+
+	store, err := secret.Open()
+	if err != nil {
+		return "", err
+	}
+	key := "wireguard/" + configID
+	updated, _ := SetPrivateKey(content, secret.Ref(store, key))
+	if err := store.Set(key, privateKey); err != nil {
+		return "", err
+	}
+	return updated, nil
+}
@@ -0,0 +1,80 @@
+package wireguard
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `[Interface]
+PrivateKey = yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=
+Address = 10.0.0.2/32
+
+[Peer]
+PublicKey = xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=
+AllowedIPs = 10.0.0.0/24
+Endpoint = fra1.portmap.io:51820
+
+[portmap]
+config_id = 42
+`
+
+func TestParseConfigReader(t *testing.T) {
+	cfg, configID, err := ParseConfigReader(strings.NewReader(testConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, "42", configID)
+	assert.Equal(t, "10.0.0.2/32", cfg.Interface.Address)
+	assert.Equal(t, "fra1.portmap.io:51820", cfg.Peer.Endpoint)
+	assert.Equal(t, []string{"10.0.0.0/24"}, cfg.Peer.AllowedIPs)
+	assert.Equal(t, 25, cfg.Peer.PersistentKeepalive)
+
+	_, _, err = ParseConfigReader(strings.NewReader("[Interface]\nAddress = 10.0.0.2/32\n"))
+	assert.EqualError(t, err, "config_id not found in [portmap] section")
+}
+
+func TestDecodeConfig(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(testConfig))
+	wrapped := encoded[:40] + "\n" + encoded[40:]
+
+	tests := []struct {
+		name    string
+		input   string
+		isValid bool
+	}{
+		{"raw", testConfig, true},
+		{"base64", encoded, true},
+		{"wrapped base64", wrapped, true},
+		{"unpadded base64", strings.TrimRight(encoded, "="), true},
+		{"empty", "  ", false},
+		{"garbage", "not a config", false},
+		{"base64 of garbage", base64.StdEncoding.EncodeToString([]byte("hello")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := DecodeConfig(tt.input)
+			if !tt.isValid {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(testConfig), strings.TrimSpace(string(data)))
+		})
+	}
+}
+
+func TestSetPrivateKey(t *testing.T) {
+	content, oldKey := SetPrivateKey(testConfig, "keyring:wireguard/42")
+	assert.Equal(t, "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=", oldKey)
+	assert.Contains(t, content, "PrivateKey = keyring:wireguard/42\n")
+	assert.Contains(t, content, "[portmap]\nconfig_id = 42\n")
+	assert.Equal(t, 1, strings.Count(content, "PrivateKey"))
+
+	content, oldKey = SetPrivateKey("[Interface]\nAddress = 10.0.0.2/32\n", "new")
+	assert.Equal(t, "", oldKey)
+	assert.Equal(t, "[Interface]\nPrivateKey = new\nAddress = 10.0.0.2/32\n", content)
+}
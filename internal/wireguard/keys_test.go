@@ -0,0 +1,28 @@
+package wireguard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKeyPair(t *testing.T) {
+	privateKey, publicKey, err := GenerateKeyPair()
+	require.NoError(t, err)
+	assert.Len(t, privateKey, 44)
+	assert.Len(t, publicKey, 44)
+	assert.NotEqual(t, privateKey, publicKey)
+
+	derived, err := PublicKey(privateKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, derived)
+
+	// Known vector from the wg(8) man page
+	derived, err = PublicKey("yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk=")
+	require.NoError(t, err)
+	assert.Equal(t, "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw=", derived)
+
+	_, err = PublicKey("not-base64")
+	assert.Error(t, err)
+}
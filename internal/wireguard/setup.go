@@ -8,7 +8,6 @@ import (
 	"os/exec"
 	"regexp"
 	"runtime"
-	"strconv"
 	"strings"
 
 	"golang.zx2c4.com/wireguard/conn"
@@ -219,6 +218,25 @@ func (m *Manager) addRoutes() error {
 	return nil
 }
 
+// SetPrivateKey swaps the interface private key of the running device, e.g.
+// after a key rotation; peers re-handshake with the new key on their own
+func (m *Manager) SetPrivateKey(b64Key string) error {
+	if m.device == nil {
+		return fmt.Errorf("device is not running")
+	}
+
+	privateKey, err := convertKey(b64Key)
+	if err != nil {
+		return fmt.Errorf("invalid private key: %v", err)
+	}
+
+	if err := m.device.IpcSet(fmt.Sprintf("private_key=%s\n", privateKey)); err != nil {
+		return fmt.Errorf("failed to configure device: %v", err)
+	}
+	m.config.Interface.PrivateKey = b64Key
+	return nil
+}
+
 func (m *Manager) Cleanup() {
 	if m.device != nil {
 		m.device.Close()
@@ -235,26 +253,22 @@ func (m *Manager) Cleanup() {
 }
 
 func (m *Manager) GetTrafficStats() (rx uint64, tx uint64) {
+	stats := m.Stats()
+	return stats.Rx, stats.Tx
+}
+
+// Stats reads the traffic counters and last handshake of the peer
+func (m *Manager) Stats() Stats {
 	if m.device == nil {
-		return 0, 0
+		return Stats{}
 	}
 
 	// Get device stats using IpcGet
-	stats, err := m.device.IpcGet()
+	uapi, err := m.device.IpcGet()
 	if err != nil {
-		return 0, 0
+		return Stats{}
 	}
-
-	// Parse stats from UAPI output
-	for _, line := range strings.Split(stats, "\n") {
-		if strings.HasPrefix(line, "rx_bytes=") {
-			rx, _ = strconv.ParseUint(strings.TrimPrefix(line, "rx_bytes="), 10, 64)
-		} else if strings.HasPrefix(line, "tx_bytes=") {
-			tx, _ = strconv.ParseUint(strings.TrimPrefix(line, "tx_bytes="), 10, 64)
-		}
-	}
-
-	return rx, tx
+	return parseStats(uapi)
 }
 
 func (m *Manager) GetInterfaceName() string {
@@ -0,0 +1,38 @@
+package wireguard
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stats are the traffic counters of the device and the time of the last
+// handshake with the peer, zero until the first one
+type Stats struct {
+	Rx            uint64
+	Tx            uint64
+	LastHandshake time.Time
+}
+
+// parseStats reads Stats from the UAPI output of the device
+func parseStats(uapi string) Stats {
+	var stats Stats
+	var sec, nsec int64
+	for _, line := range strings.Split(uapi, "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "rx_bytes":
+			stats.Rx, _ = strconv.ParseUint(value, 10, 64)
+		case "tx_bytes":
+			stats.Tx, _ = strconv.ParseUint(value, 10, 64)
+		case "last_handshake_time_sec":
+			sec, _ = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_nsec":
+			nsec, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	if sec != 0 || nsec != 0 {
+		stats.LastHandshake = time.Unix(sec, nsec)
+	}
+	return stats
+}
@@ -0,0 +1,19 @@
+package wireguard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStats(t *testing.T) {
+	stats := parseStats("private_key=abc\npublic_key=def\nlast_handshake_time_sec=1700000000\n" +
+		"last_handshake_time_nsec=5\ntx_bytes=1024\nrx_bytes=2048\npersistent_keepalive_interval=25\n")
+	assert.Equal(t, uint64(2048), stats.Rx)
+	assert.Equal(t, uint64(1024), stats.Tx)
+	assert.Equal(t, time.Unix(1700000000, 5), stats.LastHandshake)
+
+	stats = parseStats("last_handshake_time_sec=0\nlast_handshake_time_nsec=0\n")
+	assert.True(t, stats.LastHandshake.IsZero())
+}
@@ -6,21 +6,44 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"portmap.io/client/cmd/apply"
 	"portmap.io/client/cmd/config"
 	"portmap.io/client/cmd/connect"
+	"portmap.io/client/cmd/export"
+	"portmap.io/client/cmd/importer"
 	"portmap.io/client/cmd/initialize"
 	"portmap.io/client/cmd/mapping"
+	"portmap.io/client/cmd/regions"
+	"portmap.io/client/internal/api"
+	"portmap.io/client/internal/input"
+	"portmap.io/client/internal/output"
+	catalog "portmap.io/client/internal/regions"
 	cfg "portmap.io/client/pkg/config"
 )
 
 func main() {
 	var envFile string
+	var profile string
+	var offline bool
+	var noInput bool
+
+	// Region names feed flag help and validation, so load them before building commands
+	catalog.LoadCache()
 
 	rootCmd := &cobra.Command{
 		Use:   "portmap",
 		Short: "Portmap.io client",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if cmd.Name() == "init" {
+			cfg.SetProfile(profile)
+			api.SetOffline(offline)
+			input.SetNoInput(noInput)
+
+			// Completion loads its own config so it can fail quietly
+			switch cmd.Name() {
+			case "init", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+				return nil
+			}
+			if cmd.Parent() != nil && cmd.Parent().Name() == "completion" {
 				return nil
 			}
 
@@ -30,11 +53,23 @@ func main() {
 			}
 
 			if config.Token == "" {
-				log.Println("API token not found. Please run 'portmap init' to configure.")
+				if config.EnvFile == "" && config.Profile != cfg.DefaultProfile {
+					log.Printf("API token not found. Please run 'portmap init --profile %s' to configure.\n", config.Profile)
+				} else {
+					log.Println("API token not found. Please run 'portmap init' to configure.")
+				}
 				return fmt.Errorf("API token required")
 			}
 
 			cmd.Flags().Set("token", config.Token)
+			api.SetBaseURL(config.APIURL)
+
+			// A stale region catalog is not worth failing or waiting for a command
+			// over, the cached or builtin one is used instead. Offline there is
+			// nothing to refresh it from.
+			if !offline {
+				catalog.Refresh(api.NewClientWithTimeout(config.Token, catalog.RefreshTimeout), false)
+			}
 
 			// Set default format if not specified
 			if !cmd.Flags().Changed("output") {
@@ -46,15 +81,23 @@ func main() {
 	}
 
 	// Add env-file flag but don't store it in config
-	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", "", "Path to .env file (default: .env)")
+	rootCmd.PersistentFlags().StringVar(&envFile, "env-file", "", "Path to a legacy .env file (default: profile from config.yaml, then .env)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config profile to use (default: $PORTMAP_PROFILE or current_profile)")
 	rootCmd.PersistentFlags().String("token", "", "API token")
-	rootCmd.PersistentFlags().String("output", "json", "Output format (json, text)")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Validate and show the API requests of changes without sending them")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Show the last cached API responses when portmap.io can't be reached")
+	rootCmd.PersistentFlags().String("output", "json", "Output format ("+output.Formats+")")
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "Never prompt: report missing flags as errors (automatic when stdin is not a terminal)")
 
 	rootCmd.AddCommand(
 		initialize.NewCommand(),
 		connect.NewCommand(),
 		config.NewCommand(),
 		mapping.NewCommand(),
+		regions.NewCommand(),
+		apply.NewCommand(),
+		export.NewCommand(),
+		importer.NewCommand(),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
@@ -3,33 +3,80 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"portmap.io/client/internal/secret"
 )
 
 type Config struct {
-	Token        string
-	OutputFormat string
-	Region       string
+	Token         string
+	OutputFormat  string
+	Region        string
+	APIURL        string
+	SecretBackend string
+
+	// Profile is the profile of config.yaml the config belongs to
+	Profile string
+	// EnvFile is set when the config comes from a legacy .env file instead of a profile
+	EnvFile string
 }
 
 func LoadConfig(envFile string) (*Config, error) {
-	// Initialize with defaults
+	config, err := LoadRawConfig(envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token may only be a reference into the secret store
+	token, err := secret.Resolve(config.Token)
+	if err != nil {
+		return nil, fmt.Errorf("error reading API token: %w", err)
+	}
+	config.Token = token
+
+	return config, nil
+}
+
+// LoadRawConfig loads the config like LoadConfig but keeps secret references
+// unresolved, so they can be inspected or migrated.
+//
+// An explicit env file wins; otherwise the selected profile of config.yaml is
+// used, falling back to a legacy .env in the current directory. Environment
+// variables override either source.
+func LoadRawConfig(envFile string) (*Config, error) {
 	config := &Config{
 		OutputFormat: "json",
-		Token:        os.Getenv("PORTMAP_TOKEN"),
-		Region:       os.Getenv("PORTMAP_REGION"),
 	}
 
-	// Load specified .env file or try default
 	if envFile != "" {
 		if err := godotenv.Load(envFile); err != nil {
 			return nil, fmt.Errorf("error loading env file %s: %w", envFile, err)
 		}
+		config.EnvFile = envFile
 	} else {
-		// Try default .env in current directory
-		if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
-			return nil, fmt.Errorf("error loading .env file: %w", err)
+		file, err := ReadFile()
+		if err != nil {
+			return nil, err
+		}
+
+		name, explicit := file.profileName()
+		config.Profile = name
+		if profile, ok := file.Profiles[name]; ok {
+			config.Token = profile.Token
+			config.Region = profile.Region
+			config.APIURL = profile.APIURL
+			config.SecretBackend = profile.SecretBackend
+			if profile.Format != "" {
+				config.OutputFormat = profile.Format
+			}
+		} else if !explicit {
+			// Try legacy .env in current directory
+			if err := godotenv.Load(); err == nil {
+				config.EnvFile = ".env"
+			} else if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("error loading .env file: %w", err)
+			}
 		}
 	}
 
@@ -43,16 +90,100 @@ func LoadConfig(envFile string) (*Config, error) {
 	if region := os.Getenv("PORTMAP_REGION"); region != "" {
 		config.Region = region
 	}
+	if apiURL := os.Getenv("PORTMAP_API_URL"); apiURL != "" {
+		config.APIURL = apiURL
+	}
+
+	// The secret store is selected through the environment, so export the profile's choice
+	if backend := os.Getenv(secret.BackendEnvVar); backend != "" {
+		config.SecretBackend = backend
+	} else if config.SecretBackend != "" {
+		os.Setenv(secret.BackendEnvVar, config.SecretBackend)
+	}
 
 	return config, nil
 }
 
-func SaveConfig(token, format, region string) error {
-	env := map[string]string{
-		"PORTMAP_TOKEN":  token,
-		"PORTMAP_FORMAT": format,
-		"PORTMAP_REGION": region,
+// SaveConfig writes cfg back to its legacy .env file, or otherwise to its
+// profile in config.yaml
+func SaveConfig(cfg *Config) error {
+	if cfg.EnvFile != "" {
+		values := map[string]string{
+			"PORTMAP_TOKEN":  cfg.Token,
+			"PORTMAP_FORMAT": cfg.OutputFormat,
+			"PORTMAP_REGION": cfg.Region,
+		}
+		if cfg.APIURL != "" {
+			values["PORTMAP_API_URL"] = cfg.APIURL
+		}
+		if cfg.SecretBackend != "" {
+			values[secret.BackendEnvVar] = cfg.SecretBackend
+		}
+		return updateEnvFile(cfg.EnvFile, values)
+	}
+
+	file, err := ReadFile()
+	if err != nil {
+		return err
+	}
+
+	name := cfg.Profile
+	if name == "" {
+		name = DefaultProfile
+	}
+	file.Profiles[name] = &Profile{
+		Token:         cfg.Token,
+		Region:        cfg.Region,
+		Format:        cfg.OutputFormat,
+		APIURL:        cfg.APIURL,
+		SecretBackend: cfg.SecretBackend,
+	}
+	if file.CurrentProfile == "" {
+		file.CurrentProfile = name
+	}
+
+	return WriteFile(file)
+}
+
+// updateEnvFile sets values in an env file, keeping every other line of it intact
+func updateEnvFile(path string, values map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading env file %s: %w", path, err)
+	}
+
+	var lines []string
+	if content := strings.TrimRight(string(data), "\n"); content != "" {
+		lines = strings.Split(content, "\n")
+	}
+
+	written := make(map[string]bool)
+	for i, line := range lines {
+		key, _, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		key = strings.TrimSpace(key)
+		if value, ok := values[key]; found && ok {
+			entry, err := godotenv.Marshal(map[string]string{key: value})
+			if err != nil {
+				return err
+			}
+			lines[i] = entry
+			written[key] = true
+		}
+	}
+
+	remaining := make(map[string]string)
+	for key, value := range values {
+		if !written[key] {
+			remaining[key] = value
+		}
+	}
+	if len(remaining) > 0 {
+		entries, err := godotenv.Marshal(remaining)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, entries)
 	}
 
-	return godotenv.Write(env, ".env")
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
 }
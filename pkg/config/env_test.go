@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// isolate points the config file and working directory at temp dirs and clears PORTMAP_* variables
+func isolate(t *testing.T) string {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, key := range []string{"PORTMAP_TOKEN", "PORTMAP_FORMAT", "PORTMAP_REGION", "PORTMAP_API_URL", "PORTMAP_PROFILE", "PORTMAP_SECRET_BACKEND"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	SetProfile("")
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestProfiles(t *testing.T) {
+	isolate(t)
+
+	require.NoError(t, SaveConfig(&Config{Token: "dev-token", OutputFormat: "text", Region: "fra1", Profile: "dev"}))
+	require.NoError(t, SaveConfig(&Config{Token: "prod-token", OutputFormat: "json", Region: "nyc1", Profile: "prod", APIURL: "https://api.example.com"}))
+
+	// The first saved profile becomes the current one
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.Profile)
+	assert.Equal(t, "dev-token", cfg.Token)
+	assert.Equal(t, "text", cfg.OutputFormat)
+
+	SetProfile("prod")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-token", cfg.Token)
+	assert.Equal(t, "nyc1", cfg.Region)
+	assert.Equal(t, "https://api.example.com", cfg.APIURL)
+
+	// Environment variables override the profile
+	t.Setenv("PORTMAP_REGION", "sin1")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "sin1", cfg.Region)
+
+	// An explicitly selected profile that does not exist is empty rather than falling back to .env
+	SetProfile("missing")
+	cfg, err = LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Token)
+	assert.Equal(t, "", cfg.EnvFile)
+}
+
+func TestLegacyEnvFallback(t *testing.T) {
+	dir := isolate(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("OTHER_APP=keep me\nPORTMAP_TOKEN=legacy-token\n"), 0600))
+
+	cfg, err := LoadRawConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, ".env", cfg.EnvFile)
+	assert.Equal(t, "legacy-token", cfg.Token)
+
+	// Saving back updates the portmap keys and keeps unrelated ones
+	cfg.Token = "keyring:token"
+	cfg.Region = "fra1"
+	require.NoError(t, SaveConfig(cfg))
+
+	data, err := os.ReadFile(filepath.Join(dir, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "OTHER_APP=keep me\nPORTMAP_TOKEN=\"keyring:token\"\nPORTMAP_FORMAT=\"json\"\nPORTMAP_REGION=\"fra1\"\n", string(data))
+}
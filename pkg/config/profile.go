@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is used when no profile is selected
+const DefaultProfile = "default"
+
+// Profile holds the settings of one named profile in config.yaml
+type Profile struct {
+	Token         string `yaml:"token,omitempty"`
+	Region        string `yaml:"region,omitempty"`
+	Format        string `yaml:"format,omitempty"`
+	APIURL        string `yaml:"api_url,omitempty"`
+	SecretBackend string `yaml:"secret_backend,omitempty"`
+}
+
+// File is the user-level config file with its named profiles
+type File struct {
+	CurrentProfile string              `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+var selectedProfile string
+
+// SetProfile selects the profile to load, overriding PORTMAP_PROFILE and the
+// current_profile of config.yaml
+func SetProfile(name string) {
+	selectedProfile = name
+}
+
+// Path returns the location of the user-level config file,
+// $XDG_CONFIG_HOME/portmap/config.yaml
+func Path() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		var err error
+		dir, err = os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate config directory: %w", err)
+		}
+	}
+	return filepath.Join(dir, "portmap", "config.yaml"), nil
+}
+
+// ReadFile reads the user-level config file, returning an empty one if it does not exist yet
+func ReadFile() (*File, error) {
+	file := &File{Profiles: make(map[string]*Profile)}
+
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = make(map[string]*Profile)
+	}
+	return file, nil
+}
+
+// WriteFile saves the user-level config file, readable by the current user only
+func WriteFile(file *File) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return err
+	}
+	data := buf.Bytes()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error saving %s: %w", path, err)
+	}
+	return nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func (f *File) ProfileNames() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// profileName returns the selected profile and whether it was chosen
+// explicitly (flag or PORTMAP_PROFILE) rather than by default
+func (f *File) profileName() (string, bool) {
+	if selectedProfile != "" {
+		return selectedProfile, true
+	}
+	if name := os.Getenv("PORTMAP_PROFILE"); name != "" {
+		return name, true
+	}
+	if f.CurrentProfile != "" {
+		return f.CurrentProfile, false
+	}
+	return DefaultProfile, false
+}